@@ -0,0 +1,159 @@
+package main
+
+import (
+	"archive/zip"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// the exit code of a run that was interrupted (e.g. via Ctrl-C), following the convention of `128 + SIGINT`
+const ExitInterrupted = 130
+
+// the temporary files of the archives that are currently written (which are removed if the run is interrupted)
+var pendingArchives = map[string]*os.File{}
+var pendingArchivesMutex sync.Mutex
+
+// an archive that is written to a temporary file in the target directory first, and only renamed to its target once
+// it is complete. Thus, a crash (or Ctrl-C) never leaves a half-written archive behind under the name of a valid one
+type AtomicArchive struct {
+	Target string
+	Writer *zip.Writer
+	// checks the complete archive (at the provided path) before it is renamed to its target (optional)
+	Verify func(path string) error
+	file   *os.File
+}
+
+// creates the temporary file for an archive next to its target (so that the rename stays on the same file system)
+func CreateAtomicArchive(target string) (*AtomicArchive, error) {
+	// e.g. `.vc-output_2023-Jan-04-123456.zip` (it is never packaged itself, see `SelfExclusion`)
+	pattern := "." + strings.TrimSuffix(filepath.Base(target), ".zip") + "-*.zip"
+	f, err := os.CreateTemp(filepath.Dir(target), pattern)
+	if err != nil {
+		return nil, &WriteError{err}
+	}
+
+	// temporary files are only readable by their owner, but the archive is not a secret (like with `os.Create`)
+	f.Chmod(0644)
+
+	pendingArchivesMutex.Lock()
+	pendingArchives[f.Name()] = f
+	pendingArchivesMutex.Unlock()
+
+	return &AtomicArchive{Target: target, Writer: zip.NewWriter(f), file: f}, nil
+}
+
+// returns the path of the temporary file the archive is written to
+func (archive *AtomicArchive) TempPath() string {
+	return archive.file.Name()
+}
+
+// finishes the archive: it is closed, synced to disk, validated by re-opening it (and verified), and renamed to its target. If
+// anything failed before (i.e. `err` is not `nil`) or fails now, the temporary file is removed instead
+func (archive *AtomicArchive) Commit(err error) error {
+	defer archive.forget()
+
+	if closeErr := archive.Writer.Close(); closeErr != nil && err == nil {
+		err = &WriteError{closeErr}
+	}
+
+	if err == nil {
+		if syncErr := archive.file.Sync(); syncErr != nil {
+			err = &WriteError{syncErr}
+		}
+	}
+
+	if closeErr := archive.file.Close(); closeErr != nil && err == nil {
+		err = &WriteError{closeErr}
+	}
+
+	if err == nil {
+		err = validateArchive(archive.TempPath())
+	}
+
+	if err == nil && archive.Verify != nil {
+		err = archive.Verify(archive.TempPath())
+	}
+
+	if err == nil {
+		if renameErr := os.Rename(archive.TempPath(), archive.Target); renameErr != nil {
+			err = &WriteError{renameErr}
+		}
+	}
+
+	if err != nil {
+		os.Remove(archive.TempPath())
+		return err
+	}
+
+	syncDirectory(filepath.Dir(archive.Target))
+	return nil
+}
+
+func (archive *AtomicArchive) forget() {
+	pendingArchivesMutex.Lock()
+	delete(pendingArchives, archive.TempPath())
+	pendingArchivesMutex.Unlock()
+}
+
+// checks that the archive can be read by `archive/zip` (i.e. its central directory was written completely)
+func validateArchive(path string) error {
+	reader, err := zip.OpenReader(path)
+	if err != nil {
+		return &WriteError{err}
+	}
+
+	return reader.Close()
+}
+
+// persists a rename in the directory (best effort, since e.g. Windows does not support syncing directories)
+func syncDirectory(dir string) {
+	f, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	f.Sync()
+}
+
+// removes the temporary files of all archives that are currently written
+func removePendingArchives() {
+	pendingArchivesMutex.Lock()
+	defer pendingArchivesMutex.Unlock()
+
+	// the files are closed first, since e.g. Windows does not remove open files
+	for path, f := range pendingArchives {
+		f.Close()
+		os.Remove(path)
+		delete(pendingArchives, path)
+	}
+}
+
+// removes the temporary files of the archives that are currently written if the run is interrupted (e.g. via Ctrl-C),
+// and exits with `ExitInterrupted`. The returned function stops the handling
+func HandleInterrupts() func() {
+	signals := make(chan os.Signal, 1)
+	done := make(chan bool)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		select {
+		case sig := <-signals:
+			log.Error("Interrupted (", sig, "), removing the partial archive(s)")
+			removePendingArchives()
+			os.Exit(ExitInterrupted)
+		case <-done:
+		}
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// returns the names of the files in a directory
+func listDirectory(t *testing.T, dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+
+	return names
+}
+
+// Integration test for `zipSource()`, which must only leave the complete archive in the target directory
+func TestZipSourceWritesAtomically(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+
+	targetDir := t.TempDir()
+	target := filepath.Join(targetDir, "vc-output_2023-Jan-04.zip")
+	if _, err := zipSource("./sample-projects/sample-node-project", target, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if names := listDirectory(t, targetDir); !reflect.DeepEqual(names, []string{"vc-output_2023-Jan-04.zip"}) {
+		t.Errorf("Got: %v", names)
+	}
+
+	if info, err := os.Stat(target); err != nil || info.Mode().Perm()&0044 == 0 {
+		t.Errorf("Got: %v, %v", info, err)
+	}
+
+	if len(readZip(target).File) == 0 {
+		t.Errorf("The archive is empty")
+	}
+}
+
+// a failed run removes its temporary file, and keeps an existing archive (e.g. of an earlier run on the same day)
+func TestAtomicArchiveKeepsTargetOnFailure(t *testing.T) {
+	targetDir := t.TempDir()
+	target := filepath.Join(targetDir, "vc-output_2023-Jan-04.zip")
+	if err := os.WriteFile(target, []byte("earlier archive"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	archive, err := CreateAtomicArchive(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if filepath.Dir(archive.TempPath()) != targetDir || archive.TempPath() == target {
+		t.Errorf("Got: %s", archive.TempPath())
+	}
+
+	if _, err := archive.Writer.Create("app.js"); err != nil {
+		t.Fatal(err)
+	}
+
+	failure := &SourceError{errors.New("permission denied")}
+	if err := archive.Commit(failure); err != failure {
+		t.Errorf("Got: %v", err)
+	}
+
+	if names := listDirectory(t, targetDir); !reflect.DeepEqual(names, []string{"vc-output_2023-Jan-04.zip"}) {
+		t.Errorf("Got: %v", names)
+	}
+
+	if content, _ := os.ReadFile(target); string(content) != "earlier archive" {
+		t.Errorf("Got: %s", content)
+	}
+
+	if len(pendingArchives) != 0 {
+		t.Errorf("Got: %v", pendingArchives)
+	}
+}
+
+// an interrupted run removes the temporary files of the archives it was writing
+func TestRemovePendingArchives(t *testing.T) {
+	targetDir := t.TempDir()
+
+	for _, name := range []string{"vc-output_2023-Jan-04.zip", "vc-output-sca_2023-Jan-04.zip"} {
+		if _, err := CreateAtomicArchive(filepath.Join(targetDir, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	removePendingArchives()
+
+	if names := listDirectory(t, targetDir); len(names) != 0 {
+		t.Errorf("Got: %v", names)
+	}
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// the suffix of the upload descriptor that is written next to the output zip (if `-descriptor` is provided)
+const descriptorSuffix = ".upload.json"
+
+// the default template of the scan name (set via `-scan-name`)
+const defaultScanNameTemplate = "{app} {date} {time}"
+
+// the placeholders of the scan name template, e.g. `{branch}-{commit}`
+var scanNamePlaceholderRegex = regexp.MustCompile(`\{([a-z]+)\}`)
+
+var scanNamePlaceholders = []string{"app", "sandbox", "branch", "commit", "version", "date", "time"}
+
+// the branches whose archives are scanned as policy scans, i.e. without a sandbox
+var policyBranches = []string{"main", "master"}
+
+// tells an uploader script which Veracode application profile, sandbox and scan an archive belongs to
+type UploadDescriptor struct {
+	Archive string `json:"archive"`
+	Size    int64  `json:"size"`
+	SHA256  string `json:"sha256"`
+	// the packaging mode (`platform` or `pipeline`)
+	Mode string `json:"mode"`
+	App  string `json:"app"`
+	// empty for a policy scan
+	Sandbox  string             `json:"sandbox,omitempty"`
+	ScanName string             `json:"scanName"`
+	Branch   string             `json:"branch,omitempty"`
+	Commit   string             `json:"commit,omitempty"`
+	Modules  []DescriptorModule `json:"modules"`
+	// the version of the Veracode JavaScript Packager that wrote the archive
+	PackagerVersion string `json:"packagerVersion"`
+}
+
+// a module of the app, i.e. a folder with a `package.json` (like the root of the app, or a workspace)
+type DescriptorModule struct {
+	Name    string `json:"name"`
+	Path    string `json:"path"`
+	Version string `json:"version,omitempty"`
+}
+
+// returns the path of the upload descriptor for an output zip, e.g. `vc-output_2023-Jan-04.upload.json` for
+// `vc-output_2023-Jan-04.zip`
+func GetDescriptorPath(zipPath string) string {
+	return strings.TrimSuffix(zipPath, ".zip") + descriptorSuffix
+}
+
+// checks that the scan name template only uses known placeholders
+func ValidateScanNameTemplate(template string) error {
+	for _, match := range scanNamePlaceholderRegex.FindAllStringSubmatch(template, -1) {
+		if !contains(scanNamePlaceholders, match[1]) {
+			return fmt.Errorf("unknown placeholder `%s` (expected one of {%s})", match[0], strings.Join(scanNamePlaceholders, "}, {"))
+		}
+	}
+
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// fills the placeholders of the scan name template. Placeholders without a value (like `{commit}` outside of a git
+// repository) are dropped, as well as the whitespace and separators they leave at the start or the end
+func RenderScanName(template string, values map[string]string) string {
+	name := scanNamePlaceholderRegex.ReplaceAllStringFunc(template, func(placeholder string) string {
+		return values[strings.Trim(placeholder, "{}")]
+	})
+
+	name = strings.Join(strings.Fields(name), " ")
+	return strings.Trim(name, " -_/")
+}
+
+// returns the sandbox for a branch (or "" for the branches that are scanned as policy scans, like `main`)
+func GetSandboxForBranch(branch string) string {
+	if contains(policyBranches, branch) {
+		return ""
+	}
+
+	return branch
+}
+
+// returns the modules of the app, i.e. the folders of the source with a `package.json` (outside of 3rd party code) that
+// is part of the zip (and not e.g. the one of a test fixture)
+func GetDescriptorModules(manifest *Manifest) []DescriptorModule {
+	var modules []DescriptorModule
+	for _, entry := range manifest.Entries {
+		if !entry.Included || !HasBaseName("/"+entry.Path, "package.json") ||
+			HasPathSegment(entry.Path, "node_modules", "bower_components") {
+			continue
+		}
+
+		content, err := readSourceFile("/" + entry.Path)
+		if err != nil {
+			continue
+		}
+
+		var packageJson struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		}
+		json.Unmarshal(content, &packageJson)
+
+		module := DescriptorModule{Name: packageJson.Name, Path: path.Dir(entry.Path), Version: packageJson.Version}
+		if module.Name == "" {
+			module.Name = path.Base(module.Path)
+		}
+		modules = append(modules, module)
+	}
+
+	sort.Slice(modules, func(i, j int) bool {
+		return modules[i].Path < modules[j].Path
+	})
+
+	return modules
+}
+
+// returns the (hex-encoded) SHA-256 checksum of a file
+func GetFileChecksum(filePath string) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// builds the upload descriptor of an archive. The app name and the sandbox are taken from `-app` and `-sandbox` if
+// provided, and otherwise from the `package.json` and the current git branch
+func BuildUploadDescriptor(manifest *Manifest, appName string, sandbox string, scanNameTemplate string, now time.Time) (*UploadDescriptor, error) {
+	info, err := os.Stat(manifest.Archive)
+	if err != nil {
+		return nil, err
+	}
+
+	checksum, err := GetFileChecksum(manifest.Archive)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceRoot = manifest.Source
+	defer func() { sourceRoot = "" }()
+
+	descriptor := &UploadDescriptor{
+		Archive:         manifest.Archive,
+		Size:            info.Size(),
+		SHA256:          checksum,
+		Mode:            packagingMode,
+		App:             appName,
+		Sandbox:         sandbox,
+		Branch:          GetGitBranch(manifest.Source),
+		Commit:          GetGitCommit(manifest.Source),
+		Modules:         GetDescriptorModules(manifest),
+		PackagerVersion: AppVersion,
+	}
+
+	if descriptor.App == "" {
+		descriptor.App = GetAppName(manifest.Source)
+	}
+
+	if descriptor.Sandbox == "" {
+		descriptor.Sandbox = GetSandboxForBranch(descriptor.Branch)
+	}
+
+	version := ""
+	for _, module := range descriptor.Modules {
+		if module.Path == "." {
+			version = module.Version
+		}
+	}
+
+	shortCommit := descriptor.Commit
+	if len(shortCommit) > 7 {
+		shortCommit = shortCommit[:7]
+	}
+
+	descriptor.ScanName = RenderScanName(scanNameTemplate, map[string]string{
+		"app":     descriptor.App,
+		"sandbox": descriptor.Sandbox,
+		"branch":  descriptor.Branch,
+		"commit":  shortCommit,
+		"version": version,
+		"date":    now.Format("2006-Jan-02"),
+		"time":    now.Format("15:04:05"),
+	})
+
+	return descriptor, nil
+}
+
+// writes the upload descriptor as (indented) JSON to the provided path
+func WriteUploadDescriptor(descriptor *UploadDescriptor, path string) error {
+	content, err := json.MarshalIndent(descriptor, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, content, 0644)
+}
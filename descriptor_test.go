@@ -0,0 +1,190 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// writes the files of a test app with the provided content
+// Integration test for `BuildUploadDescriptor()` with a monorepo on a feature branch
+func TestBuildUploadDescriptor(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+
+	source := writeTestFiles(t, map[string]string{
+		"package.json":                      `{"name": "shop", "version": "2.3.0", "workspaces": ["packages/*"]}`,
+		"package-lock.json":                 "{}",
+		"src/app.js":                        "console.log('shop')",
+		"packages/api/package.json":         `{"name": "@shop/api", "version": "1.0.0"}`,
+		"packages/api/index.js":             "module.exports = {}",
+		"packages/web/package.json":         `{}`,
+		"node_modules/express/package.json": `{"name": "express"}`,
+		"test/fixtures/app/package.json":    `{"name": "fixture"}`,
+		"dist/package.json":                 `{"name": "shop-dist"}`,
+		".git/HEAD":                         "ref: refs/heads/feature/cart\n",
+		".git/packed-refs":                  "# pack-refs with: peeled fully-peeled sorted\n9fceb02d0ae598e95dc970b74767f19372d61af8 refs/heads/feature/cart\n",
+	})
+
+	archivePath := filepath.Join(t.TempDir(), "vc-output_2023-Jan-04.zip")
+	manifest, err := zipSource(source, archivePath, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2023, time.January, 4, 13, 37, 0, 0, time.UTC)
+	descriptor, err := BuildUploadDescriptor(manifest, "", "", "{app} {version} ({commit}) {date}", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checksum, err := GetFileChecksum(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if descriptor.App != "shop" || descriptor.Sandbox != "feature/cart" || descriptor.SHA256 != checksum ||
+		descriptor.Commit != "9fceb02d0ae598e95dc970b74767f19372d61af8" || descriptor.Mode != ModePlatform {
+		t.Errorf("Got: %+v", descriptor)
+	}
+
+	if descriptor.ScanName != "shop 2.3.0 (9fceb02) 2023-Jan-04" {
+		t.Errorf("Got: %s", descriptor.ScanName)
+	}
+
+	expectedModules := []DescriptorModule{
+		{Name: "shop", Path: ".", Version: "2.3.0"},
+		{Name: "@shop/api", Path: "packages/api", Version: "1.0.0"},
+		{Name: "web", Path: "packages/web"},
+	}
+	if !reflect.DeepEqual(descriptor.Modules, expectedModules) {
+		t.Errorf("Got: %+v", descriptor.Modules)
+		t.Errorf("Expected: %+v", expectedModules)
+	}
+
+	// the descriptor round-trips through JSON (which is what uploader scripts consume)
+	descriptorPath := GetDescriptorPath(archivePath)
+	if err := WriteUploadDescriptor(descriptor, descriptorPath); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(descriptorPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var written UploadDescriptor
+	if err := json.Unmarshal(content, &written); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(&written, descriptor) {
+		t.Errorf("Got: %+v", written)
+		t.Errorf("Expected: %+v", descriptor)
+	}
+
+	// `-app` and `-sandbox` take precedence
+	descriptor, err = BuildUploadDescriptor(manifest, "Shop (Prod)", "release", defaultScanNameTemplate, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if descriptor.App != "Shop (Prod)" || descriptor.Sandbox != "release" || descriptor.ScanName != "Shop (Prod) 2023-Jan-04 13:37:00" {
+		t.Errorf("Got: %+v", descriptor)
+	}
+}
+
+func TestGetSandboxForBranch(t *testing.T) {
+	branches := map[string]string{
+		"main":         "",
+		"master":       "",
+		"":             "",
+		"develop":      "develop",
+		"feature/cart": "feature/cart",
+	}
+
+	for branch, expected := range branches {
+		if got := GetSandboxForBranch(branch); got != expected {
+			t.Errorf("%s: Got: %s, Expected: %s", branch, got, expected)
+		}
+	}
+}
+
+func TestRenderScanName(t *testing.T) {
+	values := map[string]string{"app": "shop", "branch": "main", "commit": "", "date": "2023-Jan-04"}
+	templates := map[string]string{
+		defaultScanNameTemplate:   "shop 2023-Jan-04",
+		"{branch}-{commit}":       "main",
+		"{commit} {app}  {date}":  "shop 2023-Jan-04",
+		"nightly {app}/{version}": "nightly shop",
+	}
+
+	for template, expected := range templates {
+		if got := RenderScanName(template, values); got != expected {
+			t.Errorf("%s: Got: %s, Expected: %s", template, got, expected)
+		}
+	}
+
+	if err := ValidateScanNameTemplate("{app}-{build}"); err == nil {
+		t.Errorf("Expected an error for an unknown placeholder")
+	}
+
+	if err := ValidateScanNameTemplate("{app} {sandbox} {branch} {commit} {version} {date} {time}"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGetGitCommit(t *testing.T) {
+	// a loose ref
+	source := writeTestFiles(t, map[string]string{
+		".git/HEAD":            "ref: refs/heads/main\n",
+		".git/refs/heads/main": "1111111111111111111111111111111111111111\n",
+	})
+
+	if got := GetGitCommit(source); got != "1111111111111111111111111111111111111111" {
+		t.Errorf("Got: %s", got)
+	}
+
+	// a detached HEAD
+	source = writeTestFiles(t, map[string]string{".git/HEAD": "2222222222222222222222222222222222222222\n"})
+
+	if got := GetGitCommit(source); got != "2222222222222222222222222222222222222222" {
+		t.Errorf("Got: %s", got)
+	}
+
+	// a worktree, whose `.git` file points into the main repository (which has the refs)
+	repository := writeTestFiles(t, map[string]string{
+		"main/.git/refs/heads/feature/cart":  "3333333333333333333333333333333333333333\n",
+		"main/.git/worktrees/cart/HEAD":      "ref: refs/heads/feature/cart\n",
+		"main/.git/worktrees/cart/commondir": "../..\n",
+		"cart/.git":                          "gitdir: ../main/.git/worktrees/cart\n",
+		"cart/package.json":                  "{}",
+	})
+
+	source = filepath.Join(repository, "cart")
+	if got := GetGitBranch(source); got != "feature/cart" {
+		t.Errorf("Got: %s", got)
+	}
+
+	if got := GetGitCommit(source); got != "3333333333333333333333333333333333333333" {
+		t.Errorf("Got: %s", got)
+	}
+
+	// a submodule (with an absolute `gitdir`)
+	repository = writeTestFiles(t, map[string]string{".git/modules/lib/HEAD": "4444444444444444444444444444444444444444\n"})
+	gitFile := "gitdir: " + filepath.Join(repository, ".git", "modules", "lib") + "\n"
+	if err := os.MkdirAll(filepath.Join(repository, "lib"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repository, "lib", ".git"), []byte(gitFile), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := GetGitCommit(filepath.Join(repository, "lib")); got != "4444444444444444444444444444444444444444" {
+		t.Errorf("Got: %s", got)
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// the exit codes of the packager, so that scripts can tell why a run failed
+const (
+	ExitOK = 0
+	// an unexpected error
+	ExitFailure = 1
+	// invalid flags or arguments
+	ExitUsage = 2
+	// the `-source` does not exist, or a file of it could not be read
+	ExitSourceUnreadable = 3
+	// the archive (or a file next to it) could not be written
+	ExitWriteFailed = 4
+	// more "smells" were found than allowed via `-max-smells`
+	ExitSmells = 5
+	// the upload to the Veracode Platform failed
+	ExitUploadFailed = 6
+	// the archive exceeds the size limit of the Pipeline Scan
+	ExitPipelineSizeExceeded = 7
+	// the written archive does not contain what the packager decided to include
+	ExitVerificationFailed = 8
+)
+
+// an error while reading the source (like a file without read permissions)
+type SourceError struct {
+	Err error
+}
+
+func (err *SourceError) Error() string {
+	return "could not read the source: " + err.Err.Error()
+}
+
+func (err *SourceError) Unwrap() error {
+	return err.Err
+}
+
+// an error while writing the archive (like a full disk)
+type WriteError struct {
+	Err error
+}
+
+func (err *WriteError) Error() string {
+	return "could not write the archive: " + err.Err.Error()
+}
+
+func (err *WriteError) Unwrap() error {
+	return err.Err
+}
+
+// returns the exit code for an error, i.e. `ExitSourceUnreadable` for a `SourceError`, `ExitWriteFailed` for a
+// `WriteError`, `ExitVerificationFailed` for a `VerificationError`, `ExitPipelineSizeExceeded` for an archive that is too
+// large for the Pipeline Scan, and `ExitFailure` for everything else
+func GetExitCode(err error) int {
+	var sourceError *SourceError
+	var writeError *WriteError
+	var verificationError *VerificationError
+
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.As(err, &sourceError):
+		return ExitSourceUnreadable
+	case errors.As(err, &writeError):
+		return ExitWriteFailed
+	case errors.As(err, &verificationError):
+		return ExitVerificationFailed
+	case errors.Is(err, ErrPipelineSizeExceeded):
+		return ExitPipelineSizeExceeded
+	default:
+		return ExitFailure
+	}
+}
+
+// returns the exit code for an error of parsing the flags: `--help` is not a failure, while an unknown flag (or an
+// invalid value) is a usage error. The flag set already printed the usage in both cases
+func getParseExitCode(err error) int {
+	if errors.Is(err, flag.ErrHelp) {
+		return ExitOK
+	}
+
+	return ExitUsage
+}
+
+// checks that the source exists, is a directory and can be read
+func ValidateSource(source string) error {
+	info, err := os.Stat(source)
+	if err != nil {
+		return &SourceError{err}
+	}
+
+	if !info.IsDir() {
+		return &SourceError{fmt.Errorf("`%s` is not a directory", source)}
+	}
+
+	if _, err := os.ReadDir(source); err != nil {
+		return &SourceError{err}
+	}
+
+	return nil
+}
+
+// a reader for a file of the source, whose errors are `SourceError`s. This tells the read errors apart from the write
+// errors when a file is copied into the archive
+type sourceFileReader struct {
+	reader io.Reader
+}
+
+func (r *sourceFileReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if err != nil && err != io.EOF {
+		err = &SourceError{err}
+	}
+
+	return n, err
+}
+
+// copies a file of the source into the archive, and classifies the error (if any) as a `SourceError` or `WriteError`
+func copySourceFile(writer io.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return &SourceError{err}
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(writer, &sourceFileReader{f}); err != nil {
+		var sourceError *SourceError
+		if errors.As(err, &sourceError) {
+			return err
+		}
+
+		return &WriteError{err}
+	}
+
+	return nil
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func TestGetExitCode(t *testing.T) {
+	errorsToExitCodes := map[error]int{
+		nil:                                  ExitOK,
+		errors.New("unexpected"):             ExitFailure,
+		&SourceError{os.ErrPermission}:       ExitSourceUnreadable,
+		&WriteError{errors.New("disk full")}: ExitWriteFailed,
+		fmt.Errorf("zipping failed: %w", &WriteError{errors.New("disk full")}): ExitWriteFailed,
+		fmt.Errorf("%w: the archive has 250.0 MB", ErrPipelineSizeExceeded):    ExitPipelineSizeExceeded,
+	}
+
+	for err, expected := range errorsToExitCodes {
+		if got := GetExitCode(err); got != expected {
+			t.Errorf("%v: Got: %d, Expected: %d", err, got, expected)
+		}
+	}
+}
+
+func TestValidateSource(t *testing.T) {
+	source := t.TempDir()
+	file := filepath.Join(source, "app.js")
+	if err := os.WriteFile(file, []byte("console.log('app')"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ValidateSource(source); err != nil {
+		t.Error(err)
+	}
+
+	for _, invalid := range []string{file, filepath.Join(source, "missing")} {
+		if err := ValidateSource(invalid); GetExitCode(err) != ExitSourceUnreadable {
+			t.Errorf("%s: Got: %v", invalid, err)
+		}
+	}
+}
+
+func TestCheckSmellsThreshold(t *testing.T) {
+	smells := []string{"No `package-lock.json` found", "1 JavaScript file(s) look minified"}
+
+	for maxSmells, shouldFail := range map[int]bool{-1: false, 0: true, 1: true, 2: false, 3: false} {
+		if err := CheckSmellsThreshold(smells, maxSmells); (err != nil) != shouldFail {
+			t.Errorf("%d: Got: %v", maxSmells, err)
+		}
+	}
+}
+
+// a file of the source that cannot be read (here: a dangling symlink) fails the run, and removes the partial archive
+func TestZipSourceRemovesPartialArchive(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+
+	source := t.TempDir()
+	if err := os.WriteFile(filepath.Join(source, "app.js"), []byte("console.log('app')"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Symlink(filepath.Join(source, "missing.js"), filepath.Join(source, "zz-link.js")); err != nil {
+		t.Skip("symlinks are not supported: ", err)
+	}
+
+	target := filepath.Join(t.TempDir(), "test-output.zip")
+	_, err := zipSource(source, target, nil)
+	if GetExitCode(err) != ExitSourceUnreadable {
+		t.Errorf("Got: %v", err)
+	}
+
+	if _, statErr := os.Stat(target); !os.IsNotExist(statErr) {
+		t.Errorf("The partial archive was not removed")
+	}
+
+	// the same applies to the SCA zip
+	if err := os.WriteFile(filepath.Join(source, "package.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Symlink(filepath.Join(source, "missing.lock"), filepath.Join(source, "yarn.lock")); err != nil {
+		t.Fatal(err)
+	}
+
+	scaTarget := filepath.Join(t.TempDir(), "test-output-sca.zip")
+	if _, err := zipSCAFiles(source, scaTarget); GetExitCode(err) != ExitSourceUnreadable {
+		t.Errorf("Got: %v", err)
+	}
+
+	if _, statErr := os.Stat(scaTarget); !os.IsNotExist(statErr) {
+		t.Errorf("The partial SCA archive was not removed")
+	}
+}
+
+func TestZipSourceWithUnwritableTarget(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+
+	target := filepath.Join(t.TempDir(), "missing", "test-output.zip")
+	if _, err := zipSource("./sample-projects/sample-node-project", target, nil); GetExitCode(err) != ExitWriteFailed {
+		t.Errorf("Got: %v", err)
+	}
+}
+
+// calls `run()` with the arguments of the command line, and checks the exit codes it returns
+func TestRunExitCodes(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+	defer log.SetLevel(log.FatalLevel)
+
+	// the update check is not part of the tests
+	defer func(url string) { latestRelease = url }(latestRelease)
+	latestRelease = ""
+
+	source := "./sample-projects/sample-node-project"
+	target := t.TempDir()
+
+	testCases := []struct {
+		args     []string
+		expected int
+	}{
+		{[]string{"-help"}, ExitOK},
+		{[]string{"-unknown-flag"}, ExitUsage},
+		{[]string{"-quiet"}, ExitUsage},
+		{[]string{"-quiet", "-source"}, ExitUsage},
+		{[]string{"-quiet", "-source", filepath.Join(target, "missing")}, ExitSourceUnreadable},
+		{[]string{"-quiet", "-source", source, "-target", target, "-mode", "unknown"}, ExitUsage},
+		{[]string{"-quiet", "-source", source, "-target", target, "-mode", "pipeline", "-upload"}, ExitUsage},
+		{[]string{"-quiet", "-source", source, "-target", target, "-sca-only", "-upload", "-app", "my-app"}, ExitUsage},
+		{[]string{"-quiet", "-source", source, "-target", target, "-tests", "does-not-exist"}, ExitUsage},
+		{[]string{"-quiet", "-source", source, "-target", filepath.Join(target, "missing")}, ExitWriteFailed},
+		{[]string{"-quiet", "-source", source, "-target", target, "-max-smells", "0"}, ExitSmells},
+		{[]string{"-quiet", "-source", source, "-target", target}, ExitOK},
+		{[]string{"package", "-quiet", "-source", source, "-target", target}, ExitOK},
+		{[]string{"inspect"}, ExitUsage},
+		{[]string{"inspect", "-unknown-flag"}, ExitUsage},
+		{[]string{"upload", "-unknown-flag"}, ExitUsage},
+	}
+
+	for _, testCase := range testCases {
+		if got := run(testCase.args); got != testCase.expected {
+			t.Errorf("%v: Got: %d, Expected: %d", testCase.args, got, testCase.expected)
+		}
+	}
+}
+
+// a run does not inherit the configuration (or the findings) of an earlier one
+func TestRunResetsState(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+	defer log.SetLevel(log.FatalLevel)
+
+	defer func(url string) { latestRelease = url }(latestRelease)
+	latestRelease = ""
+
+	// a source without a lockfile (and without source maps)
+	bareSource := writeTestFiles(t, map[string]string{"app.js": "console.log('app')"})
+
+	source := "./sample-projects/sample-node-project"
+	target := t.TempDir()
+
+	args := []string{"-quiet", "-source", source, "-target", target, "-mode", "pipeline", "-case-sensitive", "images",
+		"-disable-test-heuristics", "storybook", "-recover-sources", "-output", "json"}
+	if got := run(args); got != ExitOK {
+		t.Fatalf("%v: Got: %d, Expected: %d", args, got, ExitOK)
+	}
+
+	// the lockfile of the sample project must not hide the missing one of the bare source
+	args = []string{"-quiet", "-source", bareSource, "-target", target, "-max-smells", "0"}
+	if got := run(args); got != ExitSmells {
+		t.Errorf("%v: Got: %d, Expected: %d", args, got, ExitSmells)
+	}
+
+	if doesSCAFileExist || doesMapFileExist {
+		t.Errorf("The smells of the earlier run were kept")
+	}
+
+	if packagingMode != ModePlatform || outputFormat != FormatText || recoverSourcesEnabled {
+		t.Errorf("Got: %s, %s, %v", packagingMode, outputFormat, recoverSourcesEnabled)
+	}
+
+	if imageRule.CaseSensitive || !GetTestHeuristic("storybook").Enabled {
+		t.Errorf("The flags of the earlier run were kept")
+	}
+}
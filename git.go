@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// returns the `.git` folder of the repository the source lies in (or "" if it is not in a git repository). The
+// repository may also be in a parent folder of the source. In a worktree or a submodule, `.git` is a file that points
+// to the actual folder (like `gitdir: ../.git/modules/lib`)
+func findGitFolder(source string) string {
+	folder, err := filepath.Abs(source)
+	if err != nil {
+		return ""
+	}
+
+	for {
+		gitFolder := filepath.Join(folder, ".git")
+		if info, err := os.Stat(gitFolder); err == nil && info.IsDir() {
+			return gitFolder
+		} else if err == nil {
+			return readGitDirFile(gitFolder)
+		}
+
+		parent := filepath.Dir(folder)
+		if parent == folder {
+			return ""
+		}
+		folder = parent
+	}
+}
+
+// returns the folder a `.git` file points to (or "" if it does not point to any)
+func readGitDirFile(gitFile string) string {
+	content, err := os.ReadFile(gitFile)
+	if err != nil || !strings.HasPrefix(string(content), "gitdir:") {
+		return ""
+	}
+
+	gitFolder := strings.TrimSpace(strings.TrimPrefix(string(content), "gitdir:"))
+	if !filepath.IsAbs(gitFolder) {
+		gitFolder = filepath.Join(filepath.Dir(gitFile), gitFolder)
+	}
+
+	return filepath.Clean(gitFolder)
+}
+
+// returns the content of `.git/HEAD`, i.e. either `ref: refs/heads/<branch>` or a commit (in a detached HEAD)
+func readGitHead(gitFolder string) string {
+	head, err := os.ReadFile(filepath.Join(gitFolder, "HEAD"))
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(head))
+}
+
+// returns the current git branch of the source (or "" if it is not in a git repository, or in a detached HEAD)
+func GetGitBranch(source string) string {
+	gitFolder := findGitFolder(source)
+	if gitFolder == "" {
+		return ""
+	}
+
+	head := readGitHead(gitFolder)
+	if !strings.HasPrefix(head, "ref: refs/heads/") {
+		return ""
+	}
+
+	return strings.TrimPrefix(head, "ref: refs/heads/")
+}
+
+// returns the commit the source is checked out at (or "" if it is not in a git repository). The ref of the branch is
+// either a file in `.git/refs/heads/`, or listed in `.git/packed-refs`
+func GetGitCommit(source string) string {
+	gitFolder := findGitFolder(source)
+	if gitFolder == "" {
+		return ""
+	}
+
+	head := readGitHead(gitFolder)
+	if !strings.HasPrefix(head, "ref: ") {
+		return head
+	}
+
+	// the refs of a worktree are shared with the main repository (which its `commondir` points to)
+	ref := strings.TrimPrefix(head, "ref: ")
+	if commit := readGitRef(gitFolder, ref); commit != "" {
+		return commit
+	}
+
+	if commonDir, err := os.ReadFile(filepath.Join(gitFolder, "commondir")); err == nil {
+		commonFolder := strings.TrimSpace(string(commonDir))
+		if !filepath.IsAbs(commonFolder) {
+			commonFolder = filepath.Join(gitFolder, commonFolder)
+		}
+
+		return readGitRef(commonFolder, ref)
+	}
+
+	return ""
+}
+
+// returns the commit of a ref (like `refs/heads/main`) of the git folder (or "" if it does not exist)
+func readGitRef(gitFolder string, ref string) string {
+	if commit, err := os.ReadFile(filepath.Join(gitFolder, filepath.FromSlash(ref))); err == nil {
+		return strings.TrimSpace(string(commit))
+	}
+
+	packedRefs, err := os.Open(filepath.Join(gitFolder, "packed-refs"))
+	if err != nil {
+		return ""
+	}
+	defer packedRefs.Close()
+
+	scanner := bufio.NewScanner(packedRefs)
+	for scanner.Scan() {
+		// e.g. `4f1c2a9e... refs/heads/main`
+		if fields := strings.Fields(scanner.Text()); len(fields) == 2 && fields[1] == ref {
+			return fields[0]
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,292 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// if `true`, the inline scripts of HTML files and server-side templates are additionally added to the zip as `.js`
+// files (set via `-extract-inline-scripts`)
+var extractInlineScriptsEnabled bool = false
+
+// the folder in the output zip that contains the extracted inline scripts (and the mapping back to the templates)
+const inlineScriptsFolder = "extracted-inline-scripts"
+
+// the extensions of the HTML files and templates whose inline scripts are extracted
+var templateExtensions = []string{".html", ".htm", ".ejs", ".hbs", ".handlebars", ".mustache", ".pug", ".jade", ".jsp", ".jspf"}
+
+// matches the event-handler attributes of HTML elements, like `onclick="save()"` (or `button(onclick="save()")` in Pug)
+var eventHandlerRegex = regexp.MustCompile(`(?i)[\s(,]on([a-z]+)\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+
+// matches `javascript:` URLs, like `href="javascript:void(0)"`
+var javascriptURLRegex = regexp.MustCompile(`(?i)[\s(,](?:href|action|src)\s*=\s*(?:"\s*javascript:([^"]*)"|'\s*javascript:([^']*)')`)
+
+// matches the tags of template engines (EJS, JSP, Handlebars/Mustache), which are not valid JavaScript
+var templateTagRegex = regexp.MustCompile(`(?s)<%.*?%>|\{\{\{.*?\}\}\}|\{\{.*?\}\}`)
+
+// matches the interpolations of Pug, like `#{user.name}`
+var pugInterpolationRegex = regexp.MustCompile(`[#!]\{[^}]*\}`)
+
+// matches a script block of Pug, like `script.` or `script(type="text/javascript").`
+var pugScriptRegex = regexp.MustCompile(`^(\s*)script\b[^\n]*\.\s*$`)
+
+// a piece of JavaScript in a template, i.e. an inline `<script>`, an event handler or a `javascript:` URL
+type InlineScriptFragment struct {
+	Line int `json:"line"`
+	// e.g. `script`, `onclick` or `javascript-url`
+	Kind string `json:"kind"`
+	code string
+}
+
+// maps an extracted script back to the template it was extracted from
+type InlineScriptMapping struct {
+	Script    string                 `json:"script"`
+	Template  string                 `json:"template"`
+	Fragments []InlineScriptFragment `json:"fragments"`
+}
+
+// check if the file is an HTML file or a template whose inline scripts may be extracted
+func IsTemplate(path string) bool {
+	return HasExtensionFold(path, templateExtensions...)
+}
+
+// returns the line (starting at 1) of the offset in the content
+func lineOfOffset(content []byte, offset int) int {
+	return strings.Count(string(content[:offset]), "\n") + 1
+}
+
+// finds the inline scripts, event handlers and `javascript:` URLs in a template
+func FindInlineScriptFragments(name string, content []byte) []InlineScriptFragment {
+	var fragments []InlineScriptFragment
+
+	// template tags are replaced by `null` (keeping their line breaks), so that the extracted scripts stay valid JavaScript
+	neutralize := func(code string) string {
+		replace := func(tag string) string {
+			return "null" + strings.Repeat("\n", strings.Count(tag, "\n"))
+		}
+
+		code = templateTagRegex.ReplaceAllStringFunc(code, replace)
+		if HasExtensionFold(name, ".pug", ".jade") {
+			code = pugInterpolationRegex.ReplaceAllStringFunc(code, replace)
+		}
+
+		return code
+	}
+
+	// the content of `<script>` blocks is not searched for event handlers (or `javascript:` URLs)
+	var scriptBlocks [][]int
+
+	for _, match := range inlineScriptRegex.FindAllSubmatchIndex(content, -1) {
+		scriptBlocks = append(scriptBlocks, match[:2])
+
+		attributes := content[match[2]:match[3]]
+		code := string(content[match[4]:match[5]])
+		if scriptSrcRegex.Match(attributes) || nonScriptTypeRegex.Match(attributes) || strings.TrimSpace(code) == "" {
+			continue
+		}
+
+		fragments = append(fragments, InlineScriptFragment{Line: lineOfOffset(content, match[4]), Kind: "script", code: neutralize(code)})
+	}
+
+	if HasExtensionFold(name, ".pug", ".jade") {
+		fragments = append(fragments, findPugScriptBlocks(content, neutralize)...)
+	}
+
+	isInScriptBlock := func(offset int) bool {
+		for _, block := range scriptBlocks {
+			if offset >= block[0] && offset < block[1] {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	for _, match := range eventHandlerRegex.FindAllSubmatchIndex(content, -1) {
+		if isInScriptBlock(match[0]) {
+			continue
+		}
+
+		valueStart, valueEnd := match[4], match[5]
+		if valueStart < 0 {
+			valueStart, valueEnd = match[6], match[7]
+		}
+
+		code := html.UnescapeString(string(content[valueStart:valueEnd]))
+		if strings.TrimSpace(code) == "" {
+			continue
+		}
+
+		fragments = append(fragments, InlineScriptFragment{
+			Line: lineOfOffset(content, valueStart),
+			Kind: "on" + strings.ToLower(string(content[match[2]:match[3]])),
+			// handlers may use `event` and `return`, so they are wrapped into a function
+			code: "(function (event) { " + neutralize(code) + " });",
+		})
+	}
+
+	for _, match := range javascriptURLRegex.FindAllSubmatchIndex(content, -1) {
+		if isInScriptBlock(match[0]) {
+			continue
+		}
+
+		valueStart, valueEnd := match[2], match[3]
+		if valueStart < 0 {
+			valueStart, valueEnd = match[4], match[5]
+		}
+
+		code := html.UnescapeString(string(content[valueStart:valueEnd]))
+		if strings.TrimSpace(code) == "" {
+			continue
+		}
+
+		fragments = append(fragments, InlineScriptFragment{
+			Line: lineOfOffset(content, valueStart),
+			Kind: "javascript-url",
+			code: "(function () { " + neutralize(code) + " });",
+		})
+	}
+
+	sort.SliceStable(fragments, func(i, j int) bool {
+		return fragments[i].Line < fragments[j].Line
+	})
+
+	return fragments
+}
+
+// finds the `script.` blocks of a Pug template, whose content are the lines that are indented deeper than the block
+func findPugScriptBlocks(content []byte, neutralize func(string) string) []InlineScriptFragment {
+	var fragments []InlineScriptFragment
+	lines := strings.Split(string(content), "\n")
+
+	for i := 0; i < len(lines); i++ {
+		match := pugScriptRegex.FindStringSubmatch(lines[i])
+		if match == nil {
+			continue
+		}
+
+		indentation := len(match[1])
+		var code []string
+		for i+1 < len(lines) {
+			line := lines[i+1]
+			if strings.TrimSpace(line) != "" && len(line)-len(strings.TrimLeft(line, " \t")) <= indentation {
+				break
+			}
+
+			code = append(code, line)
+			i++
+		}
+
+		if strings.TrimSpace(strings.Join(code, "")) != "" {
+			fragments = append(fragments, InlineScriptFragment{
+				Line: i - len(code) + 2,
+				Kind: "script",
+				code: neutralize(strings.Join(code, "\n")),
+			})
+		}
+	}
+
+	return fragments
+}
+
+// builds a script out of the fragments of a template, in which every fragment starts at the same line as in the
+// template. Fragments on the same line are separated by a `;` (if they don't end with one anyway)
+func BuildInlineScript(template string, fragments []InlineScriptFragment) []byte {
+	var script strings.Builder
+	line := 1
+	isLineEmpty := true
+	previous := ""
+
+	if len(fragments) > 0 && fragments[0].Line > 1 {
+		script.WriteString("// inline scripts of `" + template + "`, extracted by the Veracode JavaScript Packager (line numbers match the template)")
+		isLineEmpty = false
+	}
+
+	for _, fragment := range fragments {
+		if fragment.Line > line {
+			script.WriteString(strings.Repeat("\n", fragment.Line-line))
+			line = fragment.Line
+			isLineEmpty = true
+		}
+
+		if !isLineEmpty && strings.HasSuffix(strings.TrimSpace(previous), ";") {
+			script.WriteString(" ")
+		} else if !isLineEmpty {
+			script.WriteString("; ")
+		}
+
+		script.WriteString(fragment.code)
+		previous = fragment.code
+		line += strings.Count(fragment.code, "\n")
+		isLineEmpty = strings.HasSuffix(fragment.code, "\n")
+	}
+
+	script.WriteString("\n")
+	return []byte(script.String())
+}
+
+// returns the path of the extracted script of a template in the output zip, e.g.
+// `extracted-inline-scripts/views/index.ejs.js`
+func GetInlineScriptName(template string) string {
+	return inlineScriptsFolder + "/" + strings.TrimPrefix(template, "/") + ".js"
+}
+
+// extracts the inline scripts of the HTML files and templates that are part of the zip into `extracted-inline-scripts/`,
+// together with a `mapping.json` that maps every script back to its template (and the lines of its fragments)
+func addExtractedInlineScripts(writer *zip.Writer, manifest *Manifest) error {
+	var templates []string
+	for _, entry := range manifest.Entries {
+		// the extracted scripts (or their `mapping.json`) would end up twice in the zip, next to the ones of the source
+		if IsUnder(entry.Path, inlineScriptsFolder) {
+			return fmt.Errorf("the source already contains `%s` (please rename `%s/`, which `-extract-inline-scripts` writes to)",
+				entry.Path, inlineScriptsFolder)
+		}
+
+		if entry.Included && IsTemplate(entry.Path) {
+			templates = append(templates, entry.Path)
+		}
+	}
+
+	var mappings []InlineScriptMapping
+	for _, template := range templates {
+		content, err := readSourceFile("/" + template)
+		if err != nil {
+			return &SourceError{err}
+		}
+
+		fragments := FindInlineScriptFragments(template, content)
+		if len(fragments) == 0 {
+			continue
+		}
+
+		name := GetInlineScriptName(template)
+		manifest.Add(name, PathDecision{Notes: []string{fmt.Sprintf("%d inline script(s) extracted from `%s`", len(fragments), template)}, Generated: true})
+		mappings = append(mappings, InlineScriptMapping{Script: name, Template: template, Fragments: fragments})
+
+		if err := writeGeneratedFile(writer, name, BuildInlineScript(template, fragments)); err != nil {
+			return err
+		}
+	}
+
+	if len(mappings) == 0 {
+		return nil
+	}
+
+	log.Info("\tExtracted the inline scripts of ", len(mappings), " template(s) into `", inlineScriptsFolder, "/`")
+
+	mapping, err := json.MarshalIndent(mappings, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	mappingName := inlineScriptsFolder + "/mapping.json"
+	manifest.Add(mappingName, PathDecision{Notes: []string{"maps the extracted inline scripts back to their templates"}, Generated: true})
+	return writeGeneratedFile(writer, mappingName, mapping)
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Tests that inline scripts, event handlers and `javascript:` URLs are found (with the lines they start at)
+func TestFindInlineScriptFragments(t *testing.T) {
+	content := "<html>\n<head>\n<script src=\"/app.js\"></script>\n<script>\n  var user = \"<%= user.name %>\";\n</script>\n" +
+		"</head>\n<body onload=\"init()\">\n<a href=\"javascript:save(&quot;x&quot;)\" onclick='return check(event)'>Save</a>\n" +
+		"<script type=\"text/x-template\"><div></div></script>\n</body>\n</html>\n"
+
+	fragments := FindInlineScriptFragments("views/index.ejs", []byte(content))
+
+	expected := []InlineScriptFragment{{Line: 4, Kind: "script"}, {Line: 8, Kind: "onload"}, {Line: 9, Kind: "onclick"}, {Line: 9, Kind: "javascript-url"}}
+	if len(fragments) != len(expected) {
+		t.Fatalf("Got: %+v", fragments)
+	}
+
+	for i, fragment := range fragments {
+		if fragment.Line != expected[i].Line || fragment.Kind != expected[i].Kind {
+			t.Errorf("Got: %+v, Expected: %+v", fragment, expected[i])
+		}
+	}
+
+	script := string(BuildInlineScript("views/index.ejs", fragments))
+	lines := strings.Split(script, "\n")
+
+	for line, expectedContent := range map[int]string{
+		5: `  var user = "null";`,
+		8: `(function (event) { init() });`,
+		9: `(function (event) { return check(event) }); (function () { save("x") });`,
+	} {
+		if lines[line-1] != expectedContent {
+			t.Errorf("line %d is `%s`, expected `%s`", line, lines[line-1], expectedContent)
+		}
+	}
+}
+
+// Tests the `script.` blocks of Pug templates
+func TestFindInlineScriptFragmentsWithPug(t *testing.T) {
+	content := "html\n  body\n    button(onclick=\"go()\") Go\n    script.\n      var name = '#{name}';\n      start();\n    p done\n"
+
+	fragments := FindInlineScriptFragments("views/index.pug", []byte(content))
+	if len(fragments) != 2 || fragments[0].Kind != "onclick" || fragments[1].Line != 5 {
+		t.Fatalf("Got: %+v", fragments)
+	}
+
+	lines := strings.Split(string(BuildInlineScript("views/index.pug", fragments)), "\n")
+	if lines[4] != "      var name = 'null';" || lines[5] != "      start();" {
+		t.Errorf("Got: %v", lines)
+	}
+}
+
+// Integration test for `zipSource()` with `-extract-inline-scripts`
+func TestZipSourceWithExtractedInlineScripts(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+
+	source := writeTestFiles(t, map[string]string{
+		"package.json":      `{"name": "legacy-app"}`,
+		"views/index.hbs":   "<h1>{{title}}</h1>\n<script>\n  var title = '{{title}}';\n</script>\n",
+		"views/plain.html":  "<h1>No scripts</h1>\n",
+		"test/fixture.html": "<script>alert(1)</script>\n",
+	})
+
+	extractInlineScriptsEnabled = true
+	defer func() { extractInlineScriptsEnabled = false }()
+
+	zipFileContents := generateZipAndReturnItsFiles(source, filepath.Join(t.TempDir(), "test-output.zip"), nil)
+
+	expected := map[string]bool{
+		"extracted-inline-scripts/views/index.hbs.js":   true,
+		"extracted-inline-scripts/mapping.json":         true,
+		"extracted-inline-scripts/views/plain.html.js":  false,
+		"extracted-inline-scripts/test/fixture.html.js": false,
+	}
+
+	for name, shouldExist := range expected {
+		exists := false
+		for _, zipFile := range zipFileContents {
+			exists = exists || zipFile == name
+		}
+
+		if exists != shouldExist {
+			t.Errorf("expected `%s` to be part of the zip: %v (Got: %v)", name, shouldExist, zipFileContents)
+		}
+	}
+}
+
+// a source that already contains `extracted-inline-scripts/` (e.g. the `mapping.json` of an earlier extraction) is
+// refused, since its files would end up twice in the zip
+func TestZipSourceWithCollidingInlineScripts(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+
+	extractInlineScriptsEnabled = true
+	defer func() { extractInlineScriptsEnabled = false }()
+
+	for _, colliding := range []string{"extracted-inline-scripts/mapping.json", "extracted-inline-scripts/views/index.hbs.js"} {
+		source := writeTestFiles(t, map[string]string{
+			"views/index.hbs": "<h1>{{title}}</h1>\n<script>\n  var title = '{{title}}';\n</script>\n",
+			colliding:         "[]",
+		})
+
+		target := filepath.Join(t.TempDir(), "test-output.zip")
+		if _, err := zipSource(source, target, nil); err == nil || !strings.Contains(err.Error(), colliding) {
+			t.Errorf("%s: Got: %v", colliding, err)
+		}
+
+		if _, err := os.Stat(target); !os.IsNotExist(err) {
+			t.Errorf("%s: the partial archive was not removed", colliding)
+		}
+	}
+}
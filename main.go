@@ -4,7 +4,6 @@ import (
 	"archive/zip"
 	"flag"
 	"fmt"
-	"io"
 	"os"
 	"path"
 	"path/filepath"
@@ -20,36 +19,134 @@ var doesSCAFileExist bool = false
 var doesMapFileExist bool = false
 
 func main() {
-	// parse all the command line flags
-	sourcePtr := flag.String("source", "", "The path of the JavaScript app you want to package (required)")
-	targetPtr := flag.String("target", ".", "The path where you want the vc-output.zip to be stored to")
-	testsPtr := flag.String("tests", "", "The path that contains your test files (relative to the source). Uses a heuristic to identifiy tests automatically in case no path is provided")
+	os.Exit(run(os.Args[1:]))
+}
+
+// resets the state that the flags (and the walks) of a run leave in the package variables to their defaults, so that
+// a run does not inherit the configuration of an earlier one (e.g. in the tests)
+func resetRunState() {
+	packagingMode = ModePlatform
+	outputFormat = FormatText
+	color.NoColor = defaultNoColor
+	color.Output = defaultColorOutput
+	testsMode = TestsModeReplace
+	activeProfile = genericProfile
+	didPrintProfileMsg = false
+	sourceRoot = ""
+	tsProjects = nil
+	testRunnerRules = nil
+	recoverSourcesEnabled = false
+	extractSFCScriptsEnabled = false
+	extractInlineScriptsEnabled = false
+	vendoredDetectionEnabled = true
+	minifiedLineLengthThreshold = defaultMinifiedLineLengthThreshold
+	doesSCAFileExist = false
+	doesMapFileExist = false
+
+	for _, rule := range extensionRules {
+		rule.CaseSensitive = false
+	}
+
+	resetTestHeuristics()
+	resetPrintedMessages()
+}
+
+// runs the packager with the provided arguments, and returns its exit code (see `exitcodes.go`)
+func run(args []string) int {
+	resetRunState()
+
+	// `package` is the default subcommand, i.e. `veracode-js-packager -source .` is the same as
+	// `veracode-js-packager package -source .`
+	if len(args) > 0 && args[0] == "inspect" {
+		return runInspect(args[1:])
+	}
+
+	if len(args) > 0 && args[0] == "upload" {
+		return runUpload(args[1:])
+	}
 
-	// overwrite `flag.Usage` to print a usage example and a program description when `--help` is called
-	flag.Usage = func() {
+	if len(args) > 0 && args[0] == "package" {
+		args = args[1:]
+	}
+
+	// a Ctrl-C must not leave a partial archive behind
+	stopHandlingInterrupts := HandleInterrupts()
+	defer stopHandlingInterrupts()
+
+	// parse all the command line flags (into a flag set of their own, so that `run` can be called more than once, e.g.
+	// by the tests)
+	packageFlags := flag.NewFlagSet("veracode-js-packager", flag.ContinueOnError)
+	sourcePtr := packageFlags.String("source", "", "The path of the JavaScript app you want to package (required)")
+	targetPtr := packageFlags.String("target", ".", "The path where you want the vc-output.zip to be stored to")
+	var testsFlagValues testsFlag
+	packageFlags.Var(&testsFlagValues, "tests", "A path or glob (like `src/**/__mocks__`) that contains your test files (relative to the source). May be repeated. Uses a heuristic to identifiy tests automatically in case no path is provided")
+	enableTestHeuristicsPtr := packageFlags.String("enable-test-heuristics", "", "Comma-separated names of test heuristics to enable ("+strings.Join(GetTestHeuristicNames(), ", ")+")")
+	disableTestHeuristicsPtr := packageFlags.String("disable-test-heuristics", "", "Comma-separated names of test heuristics to disable (like `storybook,spec-folders`)")
+	testsModePtr := packageFlags.String("tests-mode", TestsModeReplace, "Whether the paths of `-tests` `replace` the heuristics for test folders (and the test runner configs), or `extend` them")
+	manifestPtr := packageFlags.Bool("manifest", false, "Write a JSON manifest next to the output zip that lists every file and whether (and why) it was omitted")
+	caseSensitivePtr := packageFlags.String("case-sensitive", "", "Comma-separated names of extension rules (like `images,documents`) that should compare file extensions case-sensitively")
+	recoverSourcesPtr := packageFlags.Bool("recover-sources", false, "Recover the original first-party sources from the `sourcesContent` of source maps (into `recovered/` in the zip), and drop the bundles they came from")
+	scaArchivePtr := packageFlags.Bool("sca-archive", false, "Additionally write a small zip (`vc-output-sca_<date>.zip`) that only contains the `package.json` files, lockfiles and Bower metadata required by Veracode SCA")
+	scaOnlyPtr := packageFlags.Bool("sca-only", false, "Only write the SCA zip (see `-sca-archive`), e.g. to cheaply run Veracode SCA on every commit")
+	extractSFCScriptsPtr := packageFlags.Bool("extract-sfc-scripts", false, "Additionally add the `<script>` blocks of Vue, Svelte and Astro components as `.js`/`.ts` files next to them (e.g. `App.vue.script.js`)")
+	extractInlineScriptsPtr := packageFlags.Bool("extract-inline-scripts", false, "Additionally add the inline scripts and event handlers of HTML files and templates (`.ejs`, `.hbs`, `.pug`, `.jsp`, ...) as `.js` files to `"+inlineScriptsFolder+"/` in the zip")
+	keepVendoredPtr := packageFlags.Bool("keep-vendored", false, "Keep vendored 3rd party libraries (like a copied `jquery.js` in `src/lib/`) instead of omitting them")
+	minifiedThresholdPtr := packageFlags.Int("minified-threshold", minifiedLineLengthThreshold, "The average line length above which a JavaScript file is considered to be minified (and thus omitted). Set to 0 to disable the line length and whitespace heuristics (bundles are still detected by the signatures of their bundlers)")
+	uploadPtr := packageFlags.Bool("upload", false, "Upload the archive to the Veracode Platform after packaging (requires `-app`, and the credentials in `VERACODE_API_KEY_ID` and `VERACODE_API_KEY_SECRET`)")
+	appPtr := packageFlags.String("app", "", "The name of the Veracode application profile to upload to (see `-upload`). Also used by `-descriptor`, which otherwise takes the `name` of the `package.json`")
+	descriptorPtr := packageFlags.Bool("descriptor", false, "Write a JSON upload descriptor next to the output zip with the app, sandbox, scan name, modules and SHA-256 checksum of the archive (for uploader scripts)")
+	sandboxPtr := packageFlags.String("sandbox", "", "The sandbox to record in the upload descriptor. Defaults to the current git branch (and to none, i.e. a policy scan, for `main` and `master`)")
+	scanNamePtr := packageFlags.String("scan-name", defaultScanNameTemplate, "The template of the scan name in the upload descriptor, with the placeholders {"+strings.Join(scanNamePlaceholders, "}, {")+"}")
+	prescanPtr := packageFlags.Bool("prescan", false, "Start the prescan after the upload (the scan starts automatically afterwards)")
+	apiBaseURLPtr := packageFlags.String("api-base-url", defaultVeracodeBaseURL, "The base URL of the Veracode API (e.g. for other regions, or a local stub server)")
+	modePtr := packageFlags.String("mode", ModePlatform, "What the archive is packaged for: `platform` (policy and sandbox scans), or `pipeline` (the Pipeline Scan, which only gets the code and has a size limit of 200 MB)")
+	profilePtr := packageFlags.String("profile", "", "The framework profile to use ("+strings.Join(GetProfileNames(), ", ")+"). The profile is detected automatically in case none is provided")
+	outputPtr := packageFlags.String("output", FormatText, "The output of the run: `text`, or `json` (no banner and colors, and a single JSON summary on stdout at the end)")
+	logFormatPtr := packageFlags.String("log-format", FormatText, "The format of the logs (on stderr): `text`, or `json` (one JSON object per line)")
+	quietPtr := packageFlags.Bool("quiet", false, "Only log warnings and errors (and omit the banner)")
+	maxSmellsPtr := packageFlags.Int("max-smells", -1, "Fail (with exit code 5) if more 'smells' than this are found. A negative value allows any number")
+
+	// overwrite the usage to print a usage example and a program description when `--help` is called
+	packageFlags.Usage = func() {
 		// the binary name of this tool
 		binaryName := filepath.Base(os.Args[0])
 
 		// may be `os.Stderr` but not necessarily
-		w := flag.CommandLine.Output()
+		w := packageFlags.Output()
 
 		fmt.Fprintf(w, "Usage of %s:\n", binaryName)
-		flag.PrintDefaults()
+		packageFlags.PrintDefaults()
 		fmt.Fprintf(w, "\nExample: \n\t%s -source ./sample-projects/sample-node-project -target .\n", binaryName)
+		fmt.Fprintf(w, "\nSubcommands: \n\t%s inspect -source <path>\tShows which profile would be used (and why)\n", binaryName)
+		fmt.Fprintf(w, "\t%s upload -file <zip> -app <name>\tUploads an existing archive to the Veracode Platform\n", binaryName)
 	}
 
-	flag.Parse()
+	if err := packageFlags.Parse(args); err != nil {
+		return getParseExitCode(err)
+	}
 
-	color.Green("#################################################")
-	color.Green("#                                               #")
-	color.Green("#   Veracode JavaScript Packager (Unofficial)   #")
-	color.Green("#                                               #")
-	color.Green("#################################################" + "\n\n")
+	startTime := time.Now()
+
+	// the output has to be configured first, since e.g. the banner would break the JSON output
+	if err := ConfigureOutput(*outputPtr, *logFormatPtr, *quietPtr); err != nil {
+		color.Red("Invalid output: %s. Run `--help` for the built-in help.", err)
+		return ExitUsage
+	}
+
+	if ShowsBanner(*quietPtr) {
+		color.Green("#################################################")
+		color.Green("#                                               #")
+		color.Green("#   Veracode JavaScript Packager (Unofficial)   #")
+		color.Green("#                                               #")
+		color.Green("#################################################" + "\n\n")
+	}
 
 	// check if the AppVersion was already set during compilation - otherwise manually get it from `./current_version`
 	CheckAppVersion()
 
-	color.Yellow("Current version: %s\n\n", AppVersion)
+	if ShowsBanner(*quietPtr) {
+		color.Yellow("Current version: %s\n\n", AppVersion)
+	}
 
 	// check if a later version of this tool exists
 	NotifyOfUpdates()
@@ -57,58 +154,328 @@ func main() {
 	// fail if `--source` was not provided
 	if *sourcePtr == "" {
 		color.Red("No `-source` was provided. Run `--help` for the built-in help.")
-		return
+		return ExitUsage
+	}
+
+	if err := ValidateSource(*sourcePtr); err != nil {
+		color.Red("Invalid `-source`: %s.", err)
+		return ExitSourceUnreadable
+	}
+
+	// extension rules are case-insensitive, unless explicitly configured otherwise
+	if err := SetCaseSensitiveExtensionRules(*caseSensitivePtr); err != nil {
+		color.Red("Invalid `-case-sensitive`: %s. Run `--help` for the built-in help.", err)
+		return ExitUsage
+	}
+
+	if err := SetTestHeuristics(*enableTestHeuristicsPtr, *disableTestHeuristicsPtr); err != nil {
+		color.Red("Invalid test heuristics: %s. Run `--help` for the built-in help.", err)
+		return ExitUsage
+	}
+
+	if err := SetTestsMode(*testsModePtr); err != nil {
+		color.Red("Invalid `-tests-mode`: %s. Run `--help` for the built-in help.", err)
+		return ExitUsage
+	}
+
+	// we want the test paths to start with a `/`, e.g. `test` would become `/test` (and `some\tests` would become
+	// `/some/tests`), so that they use the same representation as the paths the rules are checked against
+	testsPaths := NormalizeTestsPaths(testsFlagValues)
+	if err := ValidateTestsPaths(*sourcePtr, testsPaths); err != nil {
+		color.Red("Invalid `-tests`: %s. Run `--help` for the built-in help.", err)
+		return ExitUsage
+	}
+
+	if err := SetPackagingMode(*modePtr); err != nil {
+		color.Red("Invalid `-mode`: %s. Run `--help` for the built-in help.", err)
+		return ExitUsage
+	}
+
+	// the Pipeline Scan is not started via the upload API of the Platform
+	if packagingMode == ModePipeline && *uploadPtr {
+		color.Red("`-upload` is not supported with `-mode pipeline`. Run `--help` for the built-in help.")
+		return ExitUsage
+	}
+
+	// the SCA zip is not meant for the static analysis, and without it there is nothing to upload
+	if *scaOnlyPtr && *uploadPtr {
+		color.Red("`-upload` is not supported with `-sca-only`. Run `--help` for the built-in help.")
+		return ExitUsage
+	}
+
+	if err := ValidateScanNameTemplate(*scanNamePtr); err != nil {
+		color.Red("Invalid `-scan-name`: %s. Run `--help` for the built-in help.", err)
+		return ExitUsage
+	}
+
+	minifiedLineLengthThreshold = *minifiedThresholdPtr
+	recoverSourcesEnabled = *recoverSourcesPtr
+	extractSFCScriptsEnabled = *extractSFCScriptsPtr
+	extractInlineScriptsEnabled = *extractInlineScriptsPtr
+	vendoredDetectionEnabled = !*keepVendoredPtr
+
+	// choose the profile that tailors the rules to the framework of the app
+	profileReasons, err := SetActiveProfile(*sourcePtr, *profilePtr)
+	if err != nil {
+		color.Red("Invalid `-profile`: %s. Run `--help` for the built-in help.", err)
+		return ExitUsage
 	}
 
 	// add the current date to the output zip name, like e.g. "2023-Jan-04"
-	currentTime := time.Now()
-	outputZipPath := filepath.Join(*targetPtr, "vc-output_"+currentTime.Format("2006-Jan-02")+".zip")
+	currentTime := startTime
+	outputZipPath := filepath.Join(*targetPtr, GetOutputZipPrefix()+currentTime.Format("2006-Jan-02")+".zip")
+	scaZipPath := filepath.Join(*targetPtr, scaZipPrefix+currentTime.Format("2006-Jan-02")+".zip")
 
 	// echo the provided flags
-	var testsPath string
 	log.Info("Provided Flags:")
 	log.Info("\t`-source` directory to zip up: ", *sourcePtr)
 	log.Info("\t`-target` directory for the output: ", *targetPtr)
 
-	if *testsPtr == "" {
-		log.Info("\tNo `-test` directory was provided... Heuristics will be used to identify (and omit) common test directory names" + "\n\n")
-		testsPath = ""
+	if len(testsPaths) == 0 {
+		log.Info("\tNo `-tests` path was provided... Heuristics will be used to identify (and omit) common test directory names" + "\n\n")
 	} else {
-		// combine that last segment of the `sourcePtr` with the value provided via `-test`.
-		// Example: If `-test mytests` and `-source /some/node-project`, then `testsPathToLog` will be: "node-project/mytests"
-		var testsPathToLog string = filepath.Join(path.Base(*sourcePtr), *testsPtr)
-		log.Info("\tProvided `-test` directory (its content will be omitted): ", testsPathToLog, "\n\n")
+		// combine that last segment of the `sourcePtr` with the values provided via `-tests`.
+		// Example: If `-tests mytests` and `-source /some/node-project`, then the logged path will be: "node-project/mytests"
+		for _, testsPath := range testsFlagValues {
+			log.Info("\tProvided `-tests` path (its content will be omitted): ", filepath.Join(path.Base(*sourcePtr), testsPath))
+		}
 
-		// we want the test path to start with a `/`, e.g. `test` would become `/test`. This makes string matching easier
-		// as we can e.g. check if a path has the suffix `/test` instead of checking if it has the suffix `test` (the latter may be
-		// ambigious; e.g. "attest" has the suffix "test" but may contain actual source code and not tests)
-		testsPath = string(os.PathSeparator) + *testsPtr
+		if testsMode == TestsModeExtend {
+			log.Info("\tThe heuristics for common test directory names will be used as well (`-tests-mode extend`)\n\n")
+		} else {
+			log.Info("\tOnly these paths will be treated as test directories (`-tests-mode replace`)\n\n")
+		}
+	}
+
+	if packagingMode == ModePipeline {
+		log.Info("Packaging for the Pipeline Scan (`-mode pipeline`): only the code is kept\n\n")
 	}
 
+	log.Info("Using the `", activeProfile.Name, "` profile (", strings.Join(profileReasons, ", "), ")\n\n")
+
+	// the output is never packaged itself, but a `-target` inside the `-source` is worth a note (or a warning)
+	CheckTargetInSource(*sourcePtr, *targetPtr, testsPaths)
+
 	// check for some "smells" (e.g. the `package-lock.json` file is missing), and print corresponding warnings/errors
 	log.Info("Checking for 'smells' that indicate packaging issues - Started...")
-	checkForPotentialSmells(*sourcePtr)
+	smells := checkForPotentialSmells(*sourcePtr)
 	log.Info("'Smells' Check - Done\n\n")
 
+	// the SCA zip is tiny, and may be all that is needed (e.g. to run Veracode SCA on every commit)
+	if *scaArchivePtr || *scaOnlyPtr {
+		log.Info("Creating a Zip with the files required for Veracode SCA - Started...")
+		scaFiles, err := zipSCAFiles(*sourcePtr, scaZipPath)
+
+		scaManifest := &Manifest{Source: *sourcePtr, Archive: scaZipPath, Smells: smells}
+		for _, scaFile := range scaFiles {
+			scaManifest.Add(scaFile, PathDecision{})
+		}
+
+		if err != nil {
+			return finishRun(NewRunSummary(scaManifest, startTime), GetExitCode(err), err)
+		}
+
+		log.Info("SCA Zip Process - Done")
+		log.Info("Wrote SCA archive to: ", scaZipPath, "\n\n")
+
+		if *scaOnlyPtr {
+			if err := CheckSmellsThreshold(smells, *maxSmellsPtr); err != nil {
+				return finishRun(NewRunSummary(scaManifest, startTime), ExitSmells, err)
+			}
+
+			log.Info("Please upload this archive to the Veracode Platform")
+			return finishRun(NewRunSummary(scaManifest, startTime), ExitOK, nil)
+		}
+	}
+
 	log.Info("Creating a Zip while omitting non-required files - Started...")
-	// generate the zip file, and omit all non-required files
-	if err := zipSource(*sourcePtr, outputZipPath, testsPath); err != nil {
-		log.Error(err)
+	// generate the zip file, and omit all non-required files (a partial zip is removed if this fails)
+	manifest, err := zipSource(*sourcePtr, outputZipPath, testsPaths)
+
+	// the smells found while zipping (like minified JavaScript) are added to the ones found before
+	manifest.Smells = append(smells, manifest.Smells...)
+	summary := NewRunSummary(manifest, startTime)
+
+	if err != nil {
+		return finishRun(summary, GetExitCode(err), err)
 	}
 
 	log.Info("Zip Process - Done")
 	log.Info("Wrote archive to: ", outputZipPath)
-	log.Info("Please upload this archive to the Veracode Platform")
+
+	if *manifestPtr {
+		manifestPath := GetManifestPath(outputZipPath)
+		if err := WriteManifest(manifest, manifestPath); err != nil {
+			return finishRun(summary, ExitWriteFailed, &WriteError{err})
+		}
+
+		log.Info("Wrote manifest to: ", manifestPath)
+		summary.Manifest = manifestPath
+	}
+
+	if *descriptorPtr {
+		descriptorPath := GetDescriptorPath(outputZipPath)
+		descriptor, err := BuildUploadDescriptor(manifest, *appPtr, *sandboxPtr, *scanNamePtr, currentTime)
+		if err != nil {
+			return finishRun(summary, GetExitCode(err), err)
+		}
+
+		if err := WriteUploadDescriptor(descriptor, descriptorPath); err != nil {
+			return finishRun(summary, ExitWriteFailed, &WriteError{err})
+		}
+
+		log.Info("Wrote upload descriptor to: ", descriptorPath)
+		summary.Descriptor = descriptorPath
+	}
+
+	// a smelly archive is kept (so that it can be reviewed), but not uploaded
+	if err := CheckSmellsThreshold(manifest.Smells, *maxSmellsPtr); err != nil {
+		return finishRun(summary, ExitSmells, err)
+	}
+
+	if packagingMode == ModePipeline {
+		metadataPath, err := preparePipelineScan(*sourcePtr, outputZipPath)
+		if err != nil {
+			return finishRun(summary, GetExitCode(err), err)
+		}
+
+		summary.PipelineMetadata = metadataPath
+	} else if *uploadPtr {
+		if err := uploadToVeracode(*apiBaseURLPtr, *appPtr, outputZipPath, *prescanPtr); err != nil {
+			return finishRun(summary, ExitUploadFailed, err)
+		}
+	} else {
+		log.Info("Please upload this archive to the Veracode Platform")
+	}
+
+	return finishRun(summary, ExitOK, nil)
+}
+
+// logs why the run failed (if it did), prints the summary of the run, and returns its exit code
+func finishRun(summary *RunSummary, exitCode int, err error) int {
+	summary.ExitCode = exitCode
+	if err != nil {
+		log.Error(err)
+		summary.Error = err.Error()
+	}
+
+	PrintRunSummary(summary)
+	return exitCode
+}
+
+// checks that no more smells were found than allowed via `-max-smells` (a negative maximum allows any number)
+func CheckSmellsThreshold(smells []string, maxSmells int) error {
+	if maxSmells < 0 || len(smells) <= maxSmells {
+		return nil
+	}
+
+	return fmt.Errorf("found %d smell(s), but at most %d are allowed (via `-max-smells`)", len(smells), maxSmells)
 }
 
-func checkForPotentialSmells(source string) {
+// checks that the archive fits into the Pipeline Scan, and writes the metadata for the Pipeline Scan step (whose path
+// is returned)
+func preparePipelineScan(source string, archivePath string) (string, error) {
+	log.Info("Preparing the archive for the Pipeline Scan - Started...")
+	if err := CheckPipelineArchiveSize(archivePath); err != nil {
+		return "", err
+	}
+
+	metadataPath, err := WritePipelineMetadata(source, archivePath)
+	if err != nil {
+		return "", &WriteError{err}
+	}
+
+	log.Info("Wrote Pipeline Scan metadata to: ", metadataPath)
+	log.Info("Please pass this archive to the Pipeline Scan (the arguments are listed in the metadata)")
+	return metadataPath, nil
+}
+
+// the `upload` subcommand, which uploads an existing archive (e.g. one that was reviewed first)
+func runUpload(args []string) int {
+	uploadFlags := flag.NewFlagSet("upload", flag.ContinueOnError)
+	filePtr := uploadFlags.String("file", "", "The archive to upload (required)")
+	appPtr := uploadFlags.String("app", "", "The name of the Veracode application profile to upload to (required)")
+	prescanPtr := uploadFlags.Bool("prescan", false, "Start the prescan after the upload (the scan starts automatically afterwards)")
+	apiBaseURLPtr := uploadFlags.String("api-base-url", defaultVeracodeBaseURL, "The base URL of the Veracode API (e.g. for other regions, or a local stub server)")
+	if err := uploadFlags.Parse(args); err != nil {
+		return getParseExitCode(err)
+	}
+
+	if *filePtr == "" || *appPtr == "" {
+		color.Red("No `-file` or `-app` was provided. Run `upload --help` for the built-in help.")
+		return ExitUsage
+	}
+
+	if err := uploadToVeracode(*apiBaseURLPtr, *appPtr, *filePtr, *prescanPtr); err != nil {
+		log.Error(err)
+		return ExitUploadFailed
+	}
+
+	return ExitOK
+}
+
+func uploadToVeracode(baseURL string, appName string, archivePath string, prescan bool) error {
+	log.Info("Uploading the archive to the Veracode Platform - Started...")
+
+	client, err := NewVeracodeClientFromEnv(baseURL)
+	if err == nil {
+		err = UploadArchive(client, appName, archivePath, prescan)
+	}
+
+	if err != nil {
+		return fmt.Errorf("the upload failed: %w", err)
+	}
+
+	log.Info("Upload Process - Done")
+	return nil
+}
+
+// the `inspect` subcommand shows which profile would be used for the app (and why), without creating a zip
+func runInspect(args []string) int {
+	inspectFlags := flag.NewFlagSet("inspect", flag.ContinueOnError)
+	sourcePtr := inspectFlags.String("source", "", "The path of the JavaScript app you want to inspect (required)")
+	profilePtr := inspectFlags.String("profile", "", "The framework profile to use ("+strings.Join(GetProfileNames(), ", ")+"). The profile is detected automatically in case none is provided")
+	if err := inspectFlags.Parse(args); err != nil {
+		return getParseExitCode(err)
+	}
+
+	if *sourcePtr == "" {
+		color.Red("No `-source` was provided. Run `inspect --help` for the built-in help.")
+		return ExitUsage
+	}
+
+	if err := ValidateSource(*sourcePtr); err != nil {
+		color.Red("Invalid `-source`: %s.", err)
+		return ExitSourceUnreadable
+	}
+
+	reasons, err := SetActiveProfile(*sourcePtr, *profilePtr)
+	if err != nil {
+		color.Red("Invalid `-profile`: %s. Run `inspect --help` for the built-in help.", err)
+		return ExitUsage
+	}
+
+	PrintProfile(activeProfile, reasons)
+	return ExitOK
+}
+
+// checks for "smells" that indicate packaging issues, logs them, and returns them (e.g. for the manifest)
+func checkForPotentialSmells(source string) []string {
+	var smells []string
+
 	err := filepath.Walk(source, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil
 		}
 
+		// only look at the path relative to the source, so that e.g. `-source /home/me/build/my-app` does not make every
+		// path look like it is in a `build` folder
+		if relPath, err := filepath.Rel(source, path); err == nil {
+			path = relPath
+		}
+
 		// only do checks for first party code
-		if !strings.Contains(path, "node_modules") {
+		if !HasPathSegment(path, "node_modules") {
 			// check if one of the files required for SCA exists... Note that `bower.json` may be part of `bower_components`. Thus,
 			// the `if` above does not account for `bower_components` even though it has 3rd party code.
 			if !doesSCAFileExist {
@@ -116,10 +483,9 @@ func checkForPotentialSmells(source string) {
 			}
 
 			// for the remaining checks, we don't want to look into `bower_components` or any other sort of build folder
-			if !strings.Contains(path, "bower_components") && !strings.Contains(path, "build") &&
-				!strings.Contains(path, "dist") && !strings.Contains(path, "public") {
+			if !HasPathSegment(path, "bower_components", "build", "dist", "public") {
 				// check for `.map` files (only in non-3rd party and "non-build" code)
-				if strings.HasSuffix(path, ".map") {
+				if HasExtension(path, ".map") {
 					doesMapFileExist = true
 				}
 			}
@@ -135,44 +501,85 @@ func checkForPotentialSmells(source string) {
 	if !doesSCAFileExist {
 		log.Warn("\tNo `package-lock.json` or `yarn.lock` or `bower.json` file found.. (This file is required for Veracode SCA)..." +
 			" You may not receive Veracode SCA results!")
+		smells = append(smells, "No `package-lock.json`, `yarn.lock` or `bower.json` found (required for Veracode SCA)")
 	}
 
 	if doesMapFileExist {
 		log.Warn("\tThe 1st party code contains `.map` files outside of `/build`, `/dist` or `/public` (which indicates minified JavaScript)...")
 		log.Warn("\tPlease pass a directory to this tool that contains the unminified/unbundled/unconcatenated JavaScript (or TypeScript)")
+		if !recoverSourcesEnabled {
+			log.Warn("\tIf only the bundled output is available, `-recover-sources` may recover the original sources from the `.map` files")
+		}
+		smells = append(smells, "The 1st party code contains `.map` files outside of `/build`, `/dist` or `/public`")
 	}
+
+	return smells
 }
 
-func zipSource(source string, target string, testsPath string) error {
-	// 1. Create a ZIP file and zip.Writer
-	f, err := os.Create(target)
+func zipSource(source string, target string, testsPaths []string) (*Manifest, error) {
+	manifest := &Manifest{Source: source, Archive: target}
+
+	// allows rules to look at the content of files
+	sourceRoot = source
+	defer func() { sourceRoot = "" }()
+
+	// the `tsconfig` files tell which TypeScript files are tests, hand-written declarations or compiler output
+	tsProjects = LoadTsProjects(source)
+	defer func() { tsProjects = nil }()
+
+	// the configs of the test runners (like `jest.config.js`) tell exactly which files are tests
+	testRunnerRules = LoadTestRunnerRules(source)
+	defer func() { testRunnerRules = nil }()
+
+	// recover the original sources from the source maps first, since this decides which bundles are dropped
+	var recovered *RecoveredSources
+	if recoverSourcesEnabled {
+		var err error
+		if recovered, err = RecoverSources(source); err != nil {
+			return manifest, &SourceError{err}
+		}
+	}
+
+	// 1. Create a ZIP file and zip.Writer (the ZIP file is a temporary file until it is complete)
+	archive, err := CreateAtomicArchive(target)
 	if err != nil {
-		return err
+		return manifest, err
 	}
-	defer f.Close()
 
-	writer := zip.NewWriter(f)
-	defer writer.Close()
+	writer := archive.Writer
+
+	// the files this run writes (and the running executable) are excluded by their identity, not by their name
+	selfExclusion := NewSelfExclusion(archive.TempPath(), target, GetManifestPath(target), GetDescriptorPath(target),
+		GetPipelineMetadataPath(target))
+	selfExclusion.AddTargetDirectory(source, target)
+
+	// before the archive is moved to the target, it is checked to contain exactly the files of the manifest
+	archive.Verify = func(path string) error {
+		return VerifyArchive(path, manifest)
+	}
 
 	// 2. Go through all the files of the source
-	return filepath.Walk(source, func(path string, info os.FileInfo, err error) error {
+	err = filepath.Walk(source, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
-			return err
+			return &SourceError{err}
 		}
 
-		// avoids processing the created zip...
-		// 	- Say the tool is finished and an `/vc-output_2023-Jan-05.zip` is created...
-		//  - In this case, the analysis may restart with this zip as `path`
-		// 		- This edge case was observed when running the tool within a sample JS app..
+		// avoids processing the created zip (and the files next to it), as well as the Veracode JavaScript Packager
+		// binary itself - in case it is copied into the directory where the JS app resides
+		// 	- This edge case was observed when running the tool within a sample JS app..
 		//		- ... i.e., `veracode-js-packager -source . -target .`
-		if strings.HasSuffix(path, ".zip") {
-			return nil
-		}
+		// (a `-target` directory inside the source, like `-target ./out`, is skipped completely, and recorded in the
+		// manifest as a single entry)
+		if selfExclusion.Excludes(path, info) {
+			if !info.IsDir() {
+				return nil
+			}
 
-		// avoid processing the Veracode JavaScript Packager binary itself - in case it is copied into the
-		// directory where the JS app resides
-		if strings.Contains(path, "veracode-js-packager") || strings.Contains(path, "vc-js-packager") {
-			return nil
+			if relPath, err := filepath.Rel(source, path); err == nil {
+				manifest.Add(filepath.ToSlash(relPath)+"/", PathDecision{Rule: "target-directory",
+					Notes: []string{"the `-target` directory (with the output of this and earlier runs) is not packaged"}})
+			}
+			return filepath.SkipDir
 		}
 
 		// 3. Create a local file header
@@ -189,70 +596,233 @@ func zipSource(source string, target string, testsPath string) error {
 		//		- Say `-source some/path/my-js-project` is provided...
 		//			- Now, say we have a path `some/path/my-js-project/build/some.js`....
 		//		- In this scenario, we want `header.Name` to be `build/some.js`
-		header.Name, err = filepath.Rel(source, path)
+		relPath, err := filepath.Rel(source, path)
 		if err != nil {
 			return err
 		}
 
+		// zip entries always use `/` as separator (also on Windows), see the `.ZIP File Format Specification`
+		header.Name = filepath.ToSlash(relPath)
+
 		// avoids the `./` folder in the root of the output zip
 		if header.Name == "." {
 			return nil
 		}
 
-		// prepends the `/` we want before e.g. `build/some.js`
-		headerNameWithSlash := string(os.PathSeparator) + header.Name
+		// prepends the `/` we want before e.g. `build/some.js` (and appends a `/` to folders, so rules can tell them apart)
+		headerNameWithSlash := "/" + header.Name
+		if info.IsDir() {
+			headerNameWithSlash += "/"
+		}
 
-		// check if the path is required for the upload (otherwise, it will be omitted)
-		if !isRequired(headerNameWithSlash, testsPath) {
+		// check if the path is required for the upload (otherwise, it will be omitted). Bundles whose original sources
+		// were recovered are always omitted
+		var decision PathDecision
+		if recovered != nil && recovered.Bundles[headerNameWithSlash] {
+			decision = PathDecision{Rule: "recovered-bundle", Notes: []string{"its original sources were recovered into `" + recoveredFolder + "/`"}}
+		} else {
+			decision = evaluatePath(headerNameWithSlash, testsPaths)
+		}
+		if !info.IsDir() {
+			manifest.Add(header.Name, decision)
+		}
+
+		if decision.Rule != "" {
 			return nil
 		}
 
 		if info.IsDir() {
-			// add e.g. a `/` if the current path is a directory
-			header.Name += string(os.PathSeparator)
+			// add a `/` if the current path is a directory
+			header.Name += "/"
 		}
 
 		// 5. Create writer for the file header and save content of the file
 		headerWriter, err := writer.CreateHeader(header)
 		if err != nil {
-			return err
+			return &WriteError{err}
 		}
 
 		if info.IsDir() {
 			return nil
 		}
 
-		f, err := os.Open(path)
-		if err != nil {
+		return copySourceFile(headerWriter, path)
+	})
+
+	// add the recovered sources (which still have to pass the rules, e.g. to omit recovered `.scss` files)
+	if err == nil && recovered != nil {
+		err = addRecoveredSources(writer, recovered, testsPaths, manifest)
+	}
+
+	// add the `<script>` blocks of the single-file components as `.js`/`.ts` files (for a better analyzer coverage)
+	if err == nil && extractSFCScriptsEnabled {
+		err = addExtractedSFCScripts(writer, manifest)
+	}
+
+	// add the inline scripts of HTML files and templates as `.js` files (in the `extracted-inline-scripts/` folder)
+	if err == nil && extractInlineScriptsEnabled {
+		err = addExtractedInlineScripts(writer, manifest)
+	}
+
+	// the archive is only moved to the target once it is complete (a failed run must not leave a partial archive behind)
+	err = archive.Commit(err)
+
+	// JavaScript that was identified as minified/bundled by its content indicates that the wrong folder was packaged
+	var minifiedFiles []string
+	for _, entry := range manifest.Entries {
+		if entry.Rule == "minified-content" {
+			minifiedFiles = append(minifiedFiles, entry.Path)
+		}
+	}
+
+	if len(minifiedFiles) > 0 {
+		log.Warn("\tDropped ", len(minifiedFiles), " JavaScript file(s) that look minified or bundled based on their content: ",
+			strings.Join(minifiedFiles, ", "))
+		manifest.Smells = append(manifest.Smells, fmt.Sprintf("%d JavaScript file(s) look minified or bundled based on their content: %s",
+			len(minifiedFiles), strings.Join(minifiedFiles, ", ")))
+	}
+
+	CheckSingleFileComponents(manifest)
+	ReportVendoredLibraries(manifest)
+
+	return manifest, err
+}
+
+// adds the sources recovered from source maps to the zip (in the `recovered/` folder)
+func addRecoveredSources(writer *zip.Writer, recovered *RecoveredSources, testsPaths []string, manifest *Manifest) error {
+	// a file of the source with the same path would end up twice in the zip
+	sourcePaths := map[string]bool{}
+	for _, entry := range manifest.Entries {
+		sourcePaths[entry.Path] = true
+	}
+
+	for _, recoveredSource := range recovered.Sources {
+		if sourcePaths[recoveredSource.Name] {
+			return fmt.Errorf("the recovered source `%s` collides with a file of the source (please rename `%s/`)", recoveredSource.Name, recoveredFolder)
+		}
+	}
+
+	for _, recoveredSource := range recovered.Sources {
+		// the recovered content is classified, since there is no such file in the source
+		content := recoveredSource.Content
+		decision := evaluatePathWithContent("/"+recoveredSource.Name, testsPaths, func() ([]byte, error) { return content, nil })
+		decision.Notes = append(decision.Notes, "recovered from `"+recoveredSource.SourceMap+"`")
+		decision.Generated = true
+		manifest.Add(recoveredSource.Name, decision)
+
+		if decision.Rule != "" {
+			continue
+		}
+
+		if err := writeGeneratedFile(writer, recoveredSource.Name, recoveredSource.Content); err != nil {
 			return err
 		}
-		defer f.Close()
+	}
 
-		_, err = io.Copy(headerWriter, f)
-		return err
-	})
+	return nil
 }
 
-func isRequired(path string, testsPath string) bool {
-	return !IsNodeModules(path) &&
-		!IsAngularCacheFolder(path) &&
-		!IsBowerComponents(path) &&
-		!IsGitFolder(path) &&
-		!IsInTestFolder(path, testsPath) &&
-		!IsTestFile(path) &&
-		!IsStyleSheet(path) &&
-		!IsImage(path) &&
-		!IsVideo(path) &&
-		!IsDocument(path) &&
-		!IsFont(path) &&
-		!IsDb(path) &&
-		!IsBuildFolder(path) &&
-		!IsDistFolder(path) &&
-		!IsPublicFolder(path) &&
-		!IsIdeFolder(path) &&
-		!IsMinified(path) &&
-		!IsArchive(path) &&
-		!IsMiscNotRequiredFile(path)
+// the outcome of checking a path against all rules
+type PathDecision struct {
+	// the name of the rule that omits the path (empty if the path is required)
+	Rule  string
+	Notes []string
+	// set if the path is a vendored 3rd party library
+	Library *VendoredLibrary
+	// set if the file does not exist in the source (like a recovered or extracted script)
+	Generated bool
+}
+
+// a named check that omits a path if it returns `true`
+type omissionRule struct {
+	name  string
+	check func(path string) bool
+}
+
+func evaluatePath(path string, testsPaths []string) PathDecision {
+	return evaluatePathWithContent(path, testsPaths, func() ([]byte, error) { return readSourceFile(path) })
+}
+
+// checks a path against all rules like `evaluatePath`, but the rules that look at the content of a file read it via
+// `readContent`. This is needed for files that only exist in the archive (like the recovered sources)
+func evaluatePathWithContent(path string, testsPaths []string, readContent func() ([]byte, error)) PathDecision {
+	omissionRules := []omissionRule{
+		{"node_modules", IsNodeModules},
+		{"angular-cache", IsAngularCacheFolder},
+		{"profile:" + activeProfile.Name, IsOmittedByProfile},
+		{"bower_components", IsBowerComponents},
+		{"git", IsGitFolder},
+		{"test-folders", func(path string) bool { return IsInTestFolder(path, testsPaths) }},
+		{"test-runners", func(path string) bool { return UsesTestHeuristics(testsPaths) && IsTestByRunnerConfig(path) }},
+		{testFileRule.Name, IsTestFile},
+		{"typescript", IsOmittedByTsProjects},
+		{styleSheetRule.Name, IsStyleSheet},
+		{imageRule.Name, IsImage},
+		{videoRule.Name, IsVideo},
+		{documentRule.Name, IsDocument},
+		{fontRule.Name, IsFont},
+		{dbRule.Name, IsDb},
+		{"build", IsBuildFolder},
+		{"dist", IsDistFolder},
+		{"public", IsPublicFolder},
+		{"ide", IsIdeFolder},
+		{minifiedRule.Name, IsMinified},
+		{archiveRule.Name, IsArchive},
+		// hand-written `.d.ts` files and the `tsconfig` of the app are kept if the TypeScript projects say so
+		{miscExtensionRule.Name, func(path string) bool { return IsMiscNotRequiredFile(path) && !IsKeptByTsProjects(path) }},
+		// the Pipeline Scan only analyzes the code, so everything else is omitted in the `pipeline` mode
+		{"pipeline", IsOmittedInPipelineMode},
+	}
+
+	// the `build`, `dist` and `public` rules are too blunt for some frameworks, e.g. Next.js routes may lie in `app/build/`
+	outputFolderRules := map[string]bool{"build": true, "dist": true, "public": true}
+	isKeptByProfile := activeProfile.Keeps(path)
+
+	for _, rule := range omissionRules {
+		if isKeptByProfile && outputFolderRules[rule.name] {
+			continue
+		}
+
+		if !rule.check(path) {
+			continue
+		}
+
+		// `public` may also contain hand-written source (like first-party jQuery code or a service worker), which we
+		// don't want to drop. Thus, every file in it is classified
+		if rule.name == "public" {
+			return evaluatePublicFile(path, readContent)
+		}
+
+		decision := PathDecision{Rule: rule.name}
+
+		// report which config of a test runner (or which test heuristic) made the path a test
+		if rule.name == "test-runners" {
+			decision.Notes = append(decision.Notes, "test rule "+MatchTestRunnerRule(path).String())
+		} else if rule.name == "test-folders" || rule.name == testFileRule.Name {
+			if heuristic := MatchTestHeuristic(path); heuristic != nil {
+				decision.Notes = append(decision.Notes, heuristic.String())
+			}
+		}
+
+		// note if an extension rule only matched because extensions are compared case-insensitively
+		if extensionRule := GetExtensionRule(rule.name); extensionRule != nil {
+			if _, caseFolded := extensionRule.Match(path); caseFolded {
+				decision.Notes = append(decision.Notes, fmt.Sprintf("matched the `%s` rule via case folding", rule.name))
+			}
+		}
+
+		return decision
+	}
+
+	// JavaScript that passed all rules is additionally classified by its content (e.g. a webpack bundle called `main.js`)
+	if HasExtensionFold(path, ".js", ".mjs", ".cjs") && !strings.HasSuffix(path, "/") {
+		return evaluateScriptContent(path, readContent)
+	}
 
 	// the default is to not omit the file
+	return PathDecision{}
+}
+
+func isRequired(path string, testsPaths []string) bool {
+	return evaluatePath(path, testsPaths).Rule == ""
 }
@@ -3,9 +3,10 @@ package main
 import (
 	"archive/zip"
 	"os"
+	"path/filepath"
 	"reflect"
-	"regexp"
 	"sort"
+	"strings"
 
 	"testing"
 
@@ -32,15 +33,17 @@ func TestZipSourceWithNodeSample(t *testing.T) {
 	targetPath := "." + string(os.PathSeparator) + "test-output" + string(os.PathSeparator) + "test-output.zip"
 
 	// generate the zip file and return a list of all its file names
-	zipFileContents := generateZipAndReturnItsFiles(sourcePath, targetPath, "")
+	zipFileContents := generateZipAndReturnItsFiles(sourcePath, targetPath, nil)
 
 	// check if the output conforms with what we expected. To do this, we sort both the expected output and the actual output
 	// and then compare them.
 	expectedFilesInOutputZip := []string{
-		"app.js", "package.json", "package-lock.json", "testimonials-no-tests" + string(os.PathSeparator) + "should-be-included.js",
-		"distance" + string(os.PathSeparator) + "should-be-included.js", "building" + string(os.PathSeparator) + "something.js",
-		"bower_components" + string(os.PathSeparator) + "bower.json", "bower_components" + string(os.PathSeparator) + "some-thing.js",
-		"styles" + string(os.PathSeparator) + "blub.css2",
+		"app.js", "package.json", "package-lock.json", "testimonials-no-tests/should-be-included.js",
+		"distance/should-be-included.js", "building/something.js",
+		"bower_components/bower.json", "bower_components/some-thing.js",
+		"styles/blub.css2", "public/something-omittable.js",
+		// only named like the packager (only the running executable is excluded)
+		"veracode-js-packager-amd64-linux",
 	}
 	sort.Strings(expectedFilesInOutputZip)
 	sort.Strings(zipFileContents)
@@ -59,15 +62,17 @@ func TestZipSourceWithNodeSampleAndTrailingSlash(t *testing.T) {
 	targetPath := "." + string(os.PathSeparator) + "test-output" + string(os.PathSeparator) + "test-output.zip"
 
 	// generate the zip file and return a list of all its file names
-	zipFileContents := generateZipAndReturnItsFiles(sourcePath, targetPath, "")
+	zipFileContents := generateZipAndReturnItsFiles(sourcePath, targetPath, nil)
 
 	// check if the output conforms with what we expected. To do this, we sort both the expected output and the actual output
 	// and then compare them.
 	expectedFilesInOutputZip := []string{
-		"app.js", "package.json", "package-lock.json", "testimonials-no-tests" + string(os.PathSeparator) + "should-be-included.js",
-		"distance" + string(os.PathSeparator) + "should-be-included.js", "building" + string(os.PathSeparator) + "something.js",
-		"bower_components" + string(os.PathSeparator) + "bower.json", "bower_components" + string(os.PathSeparator) + "some-thing.js",
-		"styles" + string(os.PathSeparator) + "blub.css2",
+		"app.js", "package.json", "package-lock.json", "testimonials-no-tests/should-be-included.js",
+		"distance/should-be-included.js", "building/something.js",
+		"bower_components/bower.json", "bower_components/some-thing.js",
+		"styles/blub.css2", "public/something-omittable.js",
+		// only named like the packager (only the running executable is excluded)
+		"veracode-js-packager-amd64-linux",
 	}
 	sort.Strings(expectedFilesInOutputZip)
 	sort.Strings(zipFileContents)
@@ -83,18 +88,20 @@ func TestZipSourceWithNodeSampleAndTrailingSlash(t *testing.T) {
 func TestZipSourceWithNodeSampleWithTestsFlag(t *testing.T) {
 	sourcePath := "." + string(os.PathSeparator) + "sample-projects" + string(os.PathSeparator) + "sample-node-project"
 	targetPath := "." + string(os.PathSeparator) + "test-output" + string(os.PathSeparator) + "test-output.zip"
-	testsPath := "test"
+	testsPaths := NormalizeTestsPaths([]string{"test"})
 
 	// generate the zip file and return a list of all its file names
-	zipFileContents := generateZipAndReturnItsFiles(sourcePath, targetPath, testsPath)
+	zipFileContents := generateZipAndReturnItsFiles(sourcePath, targetPath, testsPaths)
 
 	// check if the output conforms with what we expected. To do this, we sort both the expected output and the actual output
 	// and then compare them.
 	expectedFilesInOutputZip := []string{
-		"app.js", "package.json", "package-lock.json", "testimonials-no-tests" + string(os.PathSeparator) + "should-be-included.js",
-		"distance" + string(os.PathSeparator) + "should-be-included.js", "building" + string(os.PathSeparator) + "something.js",
-		"bower_components" + string(os.PathSeparator) + "bower.json", "bower_components" + string(os.PathSeparator) + "some-thing.js",
-		"styles" + string(os.PathSeparator) + "blub.css2", "e2e" + string(os.PathSeparator) + "some-more-test.js",
+		"app.js", "package.json", "package-lock.json", "testimonials-no-tests/should-be-included.js",
+		"distance/should-be-included.js", "building/something.js",
+		"bower_components/bower.json", "bower_components/some-thing.js",
+		"styles/blub.css2", "e2e/some-more-test.js", "public/something-omittable.js",
+
+		"veracode-js-packager-amd64-linux",
 	}
 	sort.Strings(expectedFilesInOutputZip)
 	sort.Strings(zipFileContents)
@@ -114,99 +121,101 @@ func TestZipSourceWithAngularSample(t *testing.T) {
 	targetPath := "." + string(os.PathSeparator) + "test-output" + string(os.PathSeparator) + "test-output.zip"
 
 	// generate the zip file and return a list of all its file names
-	zipFileContents := generateZipAndReturnItsFiles(sourcePath, targetPath, "")
+	zipFileContents := generateZipAndReturnItsFiles(sourcePath, targetPath, nil)
 
 	// check if the output conforms with what we expected. To do this, we sort both the expected output and the actual output
 	// and then compare them.
 	expectedFilesInOutputZip := []string{
-		"package.json", "package-lock.json", "src" + string(os.PathSeparator) + "main.ts",
-		"src" + string(os.PathSeparator) + "test.ts",
-		"src" + string(os.PathSeparator) + "index.html",
-		"src" + string(os.PathSeparator) + "environments" + string(os.PathSeparator) + "environment.prod.ts",
-		"src" + string(os.PathSeparator) + "environments" + string(os.PathSeparator) + "environment.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "app.component.html",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "app-routing.module.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "settings" + string(os.PathSeparator) + "settings-routing.module.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "settings" + string(os.PathSeparator) + "settings.component.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "settings" + string(os.PathSeparator) + "settings.module.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "settings" + string(os.PathSeparator) + "settings.component.html",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "home" + string(os.PathSeparator) + "home-auth-resolver.service.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "home" + string(os.PathSeparator) + "home.component.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "home" + string(os.PathSeparator) + "home.module.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "home" + string(os.PathSeparator) + "home-routing.module.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "home" + string(os.PathSeparator) + "home.component.html",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "core" + string(os.PathSeparator) + "interceptors" + string(os.PathSeparator) + "http.token.interceptor.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "core" + string(os.PathSeparator) + "interceptors" + string(os.PathSeparator) + "index.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "core" + string(os.PathSeparator) + "models" + string(os.PathSeparator) + "user.model.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "core" + string(os.PathSeparator) + "models" + string(os.PathSeparator) + "comment.model.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "core" + string(os.PathSeparator) + "models" + string(os.PathSeparator) + "article-list-config.model.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "core" + string(os.PathSeparator) + "models" + string(os.PathSeparator) + "profile.model.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "core" + string(os.PathSeparator) + "models" + string(os.PathSeparator) + "index.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "core" + string(os.PathSeparator) + "models" + string(os.PathSeparator) + "errors.model.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "core" + string(os.PathSeparator) + "models" + string(os.PathSeparator) + "article.model.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "core" + string(os.PathSeparator) + "core.module.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "core" + string(os.PathSeparator) + "index.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "core" + string(os.PathSeparator) + "services" + string(os.PathSeparator) + "api.service.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "core" + string(os.PathSeparator) + "services" + string(os.PathSeparator) + "comments.service.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "core" + string(os.PathSeparator) + "services" + string(os.PathSeparator) + "profiles.service.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "core" + string(os.PathSeparator) + "services" + string(os.PathSeparator) + "tags.service.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "core" + string(os.PathSeparator) + "services" + string(os.PathSeparator) + "jwt.service.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "core" + string(os.PathSeparator) + "services" + string(os.PathSeparator) + "auth-guard.service.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "core" + string(os.PathSeparator) + "services" + string(os.PathSeparator) + "user.service.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "core" + string(os.PathSeparator) + "services" + string(os.PathSeparator) + "index.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "core" + string(os.PathSeparator) + "services" + string(os.PathSeparator) + "articles.service.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "auth" + string(os.PathSeparator) + "auth.component.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "auth" + string(os.PathSeparator) + "no-auth-guard.service.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "auth" + string(os.PathSeparator) + "auth-routing.module.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "auth" + string(os.PathSeparator) + "auth.module.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "auth" + string(os.PathSeparator) + "auth.component.html",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "shared" + string(os.PathSeparator) + "list-errors.component.html",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "shared" + string(os.PathSeparator) + "buttons" + string(os.PathSeparator) + "follow-button.component.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "shared" + string(os.PathSeparator) + "buttons" + string(os.PathSeparator) + "follow-button.component.html",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "shared" + string(os.PathSeparator) + "buttons" + string(os.PathSeparator) + "favorite-button.component.html",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "shared" + string(os.PathSeparator) + "buttons" + string(os.PathSeparator) + "index.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "shared" + string(os.PathSeparator) + "buttons" + string(os.PathSeparator) + "favorite-button.component.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "shared" + string(os.PathSeparator) + "layout" + string(os.PathSeparator) + "header.component.html",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "shared" + string(os.PathSeparator) + "layout" + string(os.PathSeparator) + "header.component.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "shared" + string(os.PathSeparator) + "layout" + string(os.PathSeparator) + "footer.component.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "shared" + string(os.PathSeparator) + "layout" + string(os.PathSeparator) + "index.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "shared" + string(os.PathSeparator) + "layout" + string(os.PathSeparator) + "footer.component.html",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "shared" + string(os.PathSeparator) + "article-helpers" + string(os.PathSeparator) + "article-list.component.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "shared" + string(os.PathSeparator) + "article-helpers" + string(os.PathSeparator) + "article-preview.component.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "shared" + string(os.PathSeparator) + "article-helpers" + string(os.PathSeparator) + "article-meta.component.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "shared" + string(os.PathSeparator) + "article-helpers" + string(os.PathSeparator) + "index.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "shared" + string(os.PathSeparator) + "article-helpers" + string(os.PathSeparator) + "article-meta.component.html",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "shared" + string(os.PathSeparator) + "article-helpers" + string(os.PathSeparator) + "article-preview.component.html",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "shared" + string(os.PathSeparator) + "article-helpers" + string(os.PathSeparator) + "article-list.component.html",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "shared" + string(os.PathSeparator) + "show-authed.directive.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "shared" + string(os.PathSeparator) + "shared.module.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "shared" + string(os.PathSeparator) + "index.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "shared" + string(os.PathSeparator) + "list-errors.component.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "app.module.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "app.component.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "profile" + string(os.PathSeparator) + "profile-favorites.component.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "profile" + string(os.PathSeparator) + "profile.component.html",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "profile" + string(os.PathSeparator) + "profile-resolver.service.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "profile" + string(os.PathSeparator) + "profile-articles.component.html",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "profile" + string(os.PathSeparator) + "profile.module.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "profile" + string(os.PathSeparator) + "profile.component.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "profile" + string(os.PathSeparator) + "profile-routing.module.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "profile" + string(os.PathSeparator) + "profile-favorites.component.html",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "profile" + string(os.PathSeparator) + "profile-articles.component.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "index.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "article" + string(os.PathSeparator) + "article.component.html",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "article" + string(os.PathSeparator) + "article-comment.component.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "article" + string(os.PathSeparator) + "article-comment.component.html",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "article" + string(os.PathSeparator) + "article.component.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "article" + string(os.PathSeparator) + "article.module.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "article" + string(os.PathSeparator) + "markdown.pipe.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "article" + string(os.PathSeparator) + "article-resolver.service.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "article" + string(os.PathSeparator) + "article-routing.module.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "editor" + string(os.PathSeparator) + "editor.component.html",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "editor" + string(os.PathSeparator) + "editor-routing.module.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "editor" + string(os.PathSeparator) + "editable-article-resolver.service.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "editor" + string(os.PathSeparator) + "editor.module.ts",
-		"src" + string(os.PathSeparator) + "app" + string(os.PathSeparator) + "editor" + string(os.PathSeparator) + "editor.component.ts",
+		"package.json", "package-lock.json", "src/main.ts",
+		"src/index.html",
+		// the `tsconfig` files of the app and its hand-written declarations are kept, while `src/test.ts` is only part
+		// of `tsconfig.spec.json`
+		"tsconfig.json", "src/tsconfig.app.json", "src/typings.d.ts",
+		"src/environments/environment.prod.ts",
+		"src/environments/environment.ts",
+		"src/app/app.component.html",
+		"src/app/app-routing.module.ts",
+		"src/app/settings/settings-routing.module.ts",
+		"src/app/settings/settings.component.ts",
+		"src/app/settings/settings.module.ts",
+		"src/app/settings/settings.component.html",
+		"src/app/home/home-auth-resolver.service.ts",
+		"src/app/home/home.component.ts",
+		"src/app/home/home.module.ts",
+		"src/app/home/home-routing.module.ts",
+		"src/app/home/home.component.html",
+		"src/app/core/interceptors/http.token.interceptor.ts",
+		"src/app/core/interceptors/index.ts",
+		"src/app/core/models/user.model.ts",
+		"src/app/core/models/comment.model.ts",
+		"src/app/core/models/article-list-config.model.ts",
+		"src/app/core/models/profile.model.ts",
+		"src/app/core/models/index.ts",
+		"src/app/core/models/errors.model.ts",
+		"src/app/core/models/article.model.ts",
+		"src/app/core/core.module.ts",
+		"src/app/core/index.ts",
+		"src/app/core/services/api.service.ts",
+		"src/app/core/services/comments.service.ts",
+		"src/app/core/services/profiles.service.ts",
+		"src/app/core/services/tags.service.ts",
+		"src/app/core/services/jwt.service.ts",
+		"src/app/core/services/auth-guard.service.ts",
+		"src/app/core/services/user.service.ts",
+		"src/app/core/services/index.ts",
+		"src/app/core/services/articles.service.ts",
+		"src/app/auth/auth.component.ts",
+		"src/app/auth/no-auth-guard.service.ts",
+		"src/app/auth/auth-routing.module.ts",
+		"src/app/auth/auth.module.ts",
+		"src/app/auth/auth.component.html",
+		"src/app/shared/list-errors.component.html",
+		"src/app/shared/buttons/follow-button.component.ts",
+		"src/app/shared/buttons/follow-button.component.html",
+		"src/app/shared/buttons/favorite-button.component.html",
+		"src/app/shared/buttons/index.ts",
+		"src/app/shared/buttons/favorite-button.component.ts",
+		"src/app/shared/layout/header.component.html",
+		"src/app/shared/layout/header.component.ts",
+		"src/app/shared/layout/footer.component.ts",
+		"src/app/shared/layout/index.ts",
+		"src/app/shared/layout/footer.component.html",
+		"src/app/shared/article-helpers/article-list.component.ts",
+		"src/app/shared/article-helpers/article-preview.component.ts",
+		"src/app/shared/article-helpers/article-meta.component.ts",
+		"src/app/shared/article-helpers/index.ts",
+		"src/app/shared/article-helpers/article-meta.component.html",
+		"src/app/shared/article-helpers/article-preview.component.html",
+		"src/app/shared/article-helpers/article-list.component.html",
+		"src/app/shared/show-authed.directive.ts",
+		"src/app/shared/shared.module.ts",
+		"src/app/shared/index.ts",
+		"src/app/shared/list-errors.component.ts",
+		"src/app/app.module.ts",
+		"src/app/app.component.ts",
+		"src/app/profile/profile-favorites.component.ts",
+		"src/app/profile/profile.component.html",
+		"src/app/profile/profile-resolver.service.ts",
+		"src/app/profile/profile-articles.component.html",
+		"src/app/profile/profile.module.ts",
+		"src/app/profile/profile.component.ts",
+		"src/app/profile/profile-routing.module.ts",
+		"src/app/profile/profile-favorites.component.html",
+		"src/app/profile/profile-articles.component.ts",
+		"src/app/index.ts",
+		"src/app/article/article.component.html",
+		"src/app/article/article-comment.component.ts",
+		"src/app/article/article-comment.component.html",
+		"src/app/article/article.component.ts",
+		"src/app/article/article.module.ts",
+		"src/app/article/markdown.pipe.ts",
+		"src/app/article/article-resolver.service.ts",
+		"src/app/article/article-routing.module.ts",
+		"src/app/editor/editor.component.html",
+		"src/app/editor/editor-routing.module.ts",
+		"src/app/editor/editable-article-resolver.service.ts",
+		"src/app/editor/editor.module.ts",
+		"src/app/editor/editor.component.ts",
 	}
 	sort.Strings(expectedFilesInOutputZip)
 	sort.Strings(zipFileContents)
@@ -220,9 +229,9 @@ func TestZipSourceWithAngularSample(t *testing.T) {
 	log.Info("---------- Finished Test: TestZipSourceWithAngularSample ----------\n\n")
 }
 
-func generateZipAndReturnItsFiles(sourcePath string, targetPath string, testsPath string) []string {
+func generateZipAndReturnItsFiles(sourcePath string, targetPath string, testsPaths []string) []string {
 	// generate the zip file, and omit all non-required files
-	if err := zipSource(sourcePath, targetPath, testsPath); err != nil {
+	if _, err := zipSource(sourcePath, targetPath, testsPaths); err != nil {
 		log.Fatal(err)
 	}
 
@@ -233,9 +242,9 @@ func generateZipAndReturnItsFiles(sourcePath string, targetPath string, testsPat
 	var zipFileContents []string
 	for _, zipFile := range zipReader.File {
 		// `zipFile.Name` contains all the paths within the zip file. It contains an element for each file (e.g. `/src/app.js`),
-		// but also an element for each folder (e.g. `/src/`). We only care about files and thus, omit every path that does not
-		// belong to a file (i.e., does not end in `.<something>`)
-		if !isPathAFile(zipFile.Name) {
+		// but also an element for each folder (e.g. `/src/`). We only care about files and thus, omit every path that belongs
+		// to a folder (i.e., ends in `/`). Files without an extension (like an executable) are kept
+		if strings.HasSuffix(zipFile.Name, "/") {
 			log.Info("Omitted path: ", zipFile.Name)
 			continue
 		}
@@ -248,6 +257,24 @@ func generateZipAndReturnItsFiles(sourcePath string, targetPath string, testsPat
 	return zipFileContents
 }
 
+// writes the files (with their content, by their path relative to the source) into a new temporary source, and
+// returns it
+func writeTestFiles(t *testing.T, files map[string]string) string {
+	source := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(source, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return source
+}
+
 // reads the zip file for the provided `zipPath` into memory and returns it
 func readZip(zipPath string) *zip.ReadCloser {
 	r, err := zip.OpenReader(zipPath)
@@ -258,14 +285,3 @@ func readZip(zipPath string) *zip.ReadCloser {
 
 	return r
 }
-
-// check if a provided path (e.g. `/some/thing/app.js` or `/some/thing/`) belongs to a file
-func isPathAFile(path string) bool {
-	isFileRegex := ".+\\.[a-zA-Z0-9]{1,8}$"
-	doesMatch, err := regexp.MatchString(isFileRegex, path)
-	if err != nil {
-		log.Error(err)
-	}
-
-	return doesMatch
-}
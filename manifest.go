@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+)
+
+// the suffix of the manifest that is written next to the output zip (if `-manifest` is provided)
+const manifestSuffix = ".manifest.json"
+
+// a single file the packager looked at, and whether it was included in the archive
+type ManifestEntry struct {
+	Path     string `json:"path"`
+	Included bool   `json:"included"`
+	// the name of the rule that omitted the file (empty if the file was included)
+	Rule  string   `json:"rule,omitempty"`
+	Notes []string `json:"notes,omitempty"`
+	// the 3rd party library the file belongs to (if it is a vendored library)
+	Library *VendoredLibrary `json:"library,omitempty"`
+	// set if the file does not exist in the source (like a recovered or extracted script)
+	Generated bool `json:"generated,omitempty"`
+}
+
+// the manifest records the decision the packager made for every file of the source
+type Manifest struct {
+	Source  string `json:"source"`
+	Archive string `json:"archive"`
+	// "smells" that indicate packaging issues (like a missing `package-lock.json`)
+	Smells  []string        `json:"smells"`
+	Entries []ManifestEntry `json:"entries"`
+}
+
+// records the decision for a path (e.g. `build/some.js`)
+func (manifest *Manifest) Add(path string, decision PathDecision) {
+	manifest.Entries = append(manifest.Entries, ManifestEntry{
+		Path:      path,
+		Included:  decision.Rule == "",
+		Rule:      decision.Rule,
+		Notes:     decision.Notes,
+		Library:   decision.Library,
+		Generated: decision.Generated,
+	})
+}
+
+// returns the path of the manifest for an output zip, e.g. `vc-output_2023-Jan-04.manifest.json` for
+// `vc-output_2023-Jan-04.zip`
+func GetManifestPath(zipPath string) string {
+	return strings.TrimSuffix(zipPath, ".zip") + manifestSuffix
+}
+
+// writes the manifest as (indented) JSON to the provided path
+func WriteManifest(manifest *Manifest, path string) error {
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, content, 0644)
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// the average line length (in characters) above which a JavaScript file is considered to be minified. The line length
+// and whitespace heuristics are disabled if this is `0` (set via `-minified-threshold`), while the signatures of the
+// bundlers are still detected
+const defaultMinifiedLineLengthThreshold = 200
+
+var minifiedLineLengthThreshold int = defaultMinifiedLineLengthThreshold
+
+// files smaller than this are too small for the line length and whitespace heuristics to be meaningful
+const minifiedMinimumSize = 512
+
+// the ratio of whitespace below which a JavaScript file is considered to be minified (regular source usually has 15-25%)
+const minifiedWhitespaceRatio = 0.05
+
+// how a JavaScript file was classified based on its content
+const (
+	ScriptSource   = "source"
+	ScriptMinified = "minified"
+	ScriptBundle   = "bundle"
+)
+
+// the runtime signatures that bundlers put into their output
+var bundlerSignatures = []struct {
+	bundler    string
+	signatures []string
+}{
+	// the runtime forms only, since e.g. `/* webpackChunkName: "about" */` is a magic comment of hand-written source
+	{"webpack", []string{"__webpack_require__", `self["webpackChunk`, "self.webpackChunk", "webpackChunk_", "webpackJsonp"}},
+	{"esbuild", []string{"__toESM(", "__commonJS("}},
+	{"rollup", []string{"typeof exports === 'object' && typeof module !== 'undefined' ? factory(exports) :"}},
+	{"parcel", []string{"parcelRequire"}},
+	{"browserify", []string{"function e(t,n,r){function s(o,u){if(!n[o]){if(!t[o])"}},
+}
+
+// matches a `//# sourceMappingURL=` (or the older `//@ sourceMappingURL=`) comment
+var sourceMappingURLRegex = regexp.MustCompile(`(?m)^\s*//[#@]\s*sourceMappingURL=`)
+
+// classifies JavaScript as `source`, `minified` or `bundle` based on its content, and returns the reasons for it. A
+// `sourceMappingURL` comment alone does not make a file minified (compiled TypeScript or Babel output of hand-written
+// code has one as well), but it halves the thresholds of the line length and whitespace heuristics
+func ClassifyScript(content []byte) (string, []string) {
+	classification := ScriptSource
+	var reasons []string
+
+	for _, bundler := range bundlerSignatures {
+		for _, signature := range bundler.signatures {
+			if bytes.Contains(content, []byte(signature)) {
+				classification = ScriptBundle
+				reasons = append(reasons, fmt.Sprintf("contains the %s runtime (`%s`)", bundler.bundler, signature))
+				break
+			}
+		}
+	}
+
+	if minifiedLineLengthThreshold <= 0 || len(content) < minifiedMinimumSize {
+		return classification, reasons
+	}
+
+	lineLengthThreshold := minifiedLineLengthThreshold
+	whitespaceRatio := minifiedWhitespaceRatio
+	hasSourceMappingURL := sourceMappingURLRegex.Match(content)
+	if hasSourceMappingURL {
+		lineLengthThreshold /= 2
+		whitespaceRatio *= 2
+	}
+
+	var minifiedReasons []string
+	averageLineLength := len(content) / (bytes.Count(content, []byte("\n")) + 1)
+	if averageLineLength > lineLengthThreshold {
+		minifiedReasons = append(minifiedReasons, fmt.Sprintf("has an average line length of %d characters (threshold: %d)",
+			averageLineLength, lineLengthThreshold))
+	}
+
+	whitespace := 0
+	for _, character := range content {
+		if character == ' ' || character == '\t' || character == '\n' || character == '\r' {
+			whitespace++
+		}
+	}
+
+	if ratio := float64(whitespace) / float64(len(content)); ratio < whitespaceRatio {
+		minifiedReasons = append(minifiedReasons, fmt.Sprintf("only consists of %.1f%% whitespace", ratio*100))
+	}
+
+	if len(minifiedReasons) == 0 {
+		return classification, reasons
+	}
+
+	if hasSourceMappingURL {
+		minifiedReasons = append(minifiedReasons, "contains a `sourceMappingURL` comment")
+	}
+
+	if classification == ScriptSource {
+		classification = ScriptMinified
+	}
+
+	return classification, append(reasons, minifiedReasons...)
+}
+
+// classifies a JavaScript file that passed all other rules by its content. Vendored libraries as well as minified and
+// bundled files are omitted
+func evaluateScriptContent(path string, readContent func() ([]byte, error)) PathDecision {
+	content, err := readContent()
+	if err != nil {
+		return PathDecision{}
+	}
+
+	if vendoredDetectionEnabled {
+		if library := DetectVendoredLibrary(path, content); library != nil {
+			log.Info("\tDropping `", path, "` (", library.Note(), ")")
+			return PathDecision{Rule: "vendored", Notes: []string{library.Note()}, Library: library}
+		}
+	}
+
+	classification, reasons := ClassifyScript(content)
+	notes := []string{"classified by content as " + classification}
+	for _, reason := range reasons {
+		notes = append(notes, classification+": "+reason)
+	}
+
+	if classification == ScriptSource {
+		return PathDecision{Notes: notes}
+	}
+
+	log.Info("\tDropping `", path, "` (classified by its content as ", classification, ")")
+	return PathDecision{Rule: "minified-content", Notes: notes}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// Tests the content heuristics for minified and bundled JavaScript
+func TestClassifyScript(t *testing.T) {
+	regularSource := strings.Repeat("function add(a, b) {\n  // adds two numbers\n  return a + b;\n}\n\n", 20)
+	minifiedSource := strings.Repeat("!function(n,t){return n+t}(1,2);", 40)
+	// lines of 177 characters, i.e. below the threshold of 200 (but above half of it)
+	longLinesSource := strings.Repeat("var values = ["+strings.Repeat("1, ", 40)+"1]; function total(v) { return v.length; };\n", 10)
+
+	testCases := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"regular source", regularSource, ScriptSource},
+		{"small one-liner", "module.exports=function(a,b){return a+b};", ScriptSource},
+		{"minified source", minifiedSource, ScriptMinified},
+		{"webpack bundle", "(() => {\n  var __webpack_modules__ = {};\n  function __webpack_require__(id) {}\n})();\n", ScriptBundle},
+		{"esbuild bundle", "var app = __toESM(require_app());\n", ScriptBundle},
+		{"webpack chunk", `(self["webpackChunkmy_app"] = self["webpackChunkmy_app"] || []).push([[179], {}]);`, ScriptBundle},
+		// the magic comment of a lazy-loaded route is hand-written source
+		{"webpackChunkName comment", "const About = () => import(/* webpackChunkName: \"about\" */ './About.vue');\n", ScriptSource},
+		// a `sourceMappingURL` comment alone (e.g. in compiled TypeScript) does not make a file minified...
+		{"source map comment", "console.log('hi');\n//# sourceMappingURL=main.js.map\n", ScriptSource},
+		{"compiled source with a source map comment", regularSource + "//# sourceMappingURL=add.js.map\n", ScriptSource},
+		// ... but it lowers the thresholds for files that look almost minified
+		{"long lines without a source map comment", longLinesSource, ScriptSource},
+		{"long lines with a source map comment", longLinesSource + "\n//# sourceMappingURL=app.js.map", ScriptMinified},
+	}
+
+	for _, testCase := range testCases {
+		if got, reasons := ClassifyScript([]byte(testCase.content)); got != testCase.expected {
+			t.Errorf("%s: classified as `%s` (%v), expected `%s`", testCase.name, got, reasons, testCase.expected)
+		}
+	}
+
+	// the threshold is configurable, and `0` disables the line length and whitespace heuristics (but not the signatures of
+	// the bundlers)
+	defer func() { minifiedLineLengthThreshold = 200 }()
+
+	minifiedLineLengthThreshold = 10000
+	if got, _ := ClassifyScript([]byte(minifiedSource)); got != ScriptMinified {
+		t.Errorf("the whitespace ratio should still classify the source as minified, got `%s`", got)
+	}
+
+	minifiedLineLengthThreshold = 0
+	if got, _ := ClassifyScript([]byte(minifiedSource)); got != ScriptSource {
+		t.Errorf("the heuristics should be disabled, got `%s`", got)
+	}
+
+	if got, _ := ClassifyScript([]byte("var app = __toESM(require_app());\n")); got != ScriptBundle {
+		t.Errorf("the bundler signatures should still be detected, got `%s`", got)
+	}
+}
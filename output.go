@@ -0,0 +1,161 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	log "github.com/sirupsen/logrus"
+)
+
+// the formats of the output of a run (set via `-output`) and of the streaming logs (set via `-log-format`)
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// if `json`, the banner and colors are suppressed, and the run ends with a single JSON summary on stdout
+var outputFormat string = FormatText
+
+// the colors and the writer of the banner as set up by the `color` package (to restore them before a run)
+var defaultNoColor = color.NoColor
+var defaultColorOutput = color.Output
+
+// the summary of a run that is printed with `-output json`
+type RunSummary struct {
+	Archive string `json:"archive"`
+	Size    int64  `json:"size"`
+	Files   struct {
+		Included int `json:"included"`
+		Omitted  int `json:"omitted"`
+		// the number of omitted files per rule, e.g. `{"node_modules": 1234, "test-folders": 56}`
+		OmittedByRule map[string]int `json:"omittedByRule"`
+	} `json:"files"`
+	Smells []string `json:"smells"`
+	// the files that were written next to the archive (if any)
+	Manifest         string `json:"manifest,omitempty"`
+	Descriptor       string `json:"descriptor,omitempty"`
+	PipelineMetadata string `json:"pipelineMetadata,omitempty"`
+	DurationMs       int64  `json:"durationMs"`
+	Version          string `json:"version"`
+	// see `exitcodes.go`, and the reason why the run failed (if it did)
+	ExitCode int    `json:"exitCode"`
+	Error    string `json:"error,omitempty"`
+}
+
+// logs JSON (like the `JSONFormatter` of `logrus`), but without the tabs and line breaks that only indent the text logs
+type trimmedJSONFormatter struct {
+	log.JSONFormatter
+}
+
+func (formatter *trimmedJSONFormatter) Format(entry *log.Entry) ([]byte, error) {
+	trimmed := *entry
+	trimmed.Message = strings.TrimSpace(entry.Message)
+	return formatter.JSONFormatter.Format(&trimmed)
+}
+
+// configures the output of the run: `-output json` suppresses the banner and colors (and moves all human-readable
+// output to stderr, so that stdout only contains the summary), `-log-format json` logs one JSON object per line, and
+// `-quiet` only logs warnings and errors
+func ConfigureOutput(output string, logFormat string, quiet bool) error {
+	if output != FormatText && output != FormatJSON {
+		return fmt.Errorf("unknown output `%s` (expected `%s` or `%s`)", output, FormatText, FormatJSON)
+	}
+
+	if logFormat != FormatText && logFormat != FormatJSON {
+		return fmt.Errorf("unknown log format `%s` (expected `%s` or `%s`)", logFormat, FormatText, FormatJSON)
+	}
+
+	outputFormat = output
+	if outputFormat == FormatJSON {
+		color.NoColor = true
+		color.Output = os.Stderr
+	}
+
+	if logFormat == FormatJSON {
+		log.SetFormatter(&trimmedJSONFormatter{})
+	} else {
+		log.SetFormatter(&log.TextFormatter{})
+	}
+
+	if quiet {
+		log.SetLevel(log.WarnLevel)
+	} else {
+		log.SetLevel(log.InfoLevel)
+	}
+
+	return nil
+}
+
+// check if the banner (and the current version) should be printed
+func ShowsBanner(quiet bool) bool {
+	return outputFormat == FormatText && !quiet
+}
+
+// summarizes a run from its manifest (and the archive it wrote)
+func NewRunSummary(manifest *Manifest, started time.Time) *RunSummary {
+	summary := &RunSummary{Smells: manifest.Smells, Version: strings.TrimSpace(AppVersion)}
+	summary.Files.OmittedByRule = map[string]int{}
+
+	// the archive of a failed run is removed
+	if info, err := os.Stat(manifest.Archive); err == nil {
+		summary.Archive = manifest.Archive
+		summary.Size = info.Size()
+	}
+
+	for _, entry := range manifest.Entries {
+		if entry.Included {
+			summary.Files.Included++
+		} else {
+			summary.Files.Omitted++
+			summary.Files.OmittedByRule[entry.Rule]++
+		}
+	}
+
+	if summary.Smells == nil {
+		summary.Smells = []string{}
+	}
+
+	summary.DurationMs = time.Since(started).Milliseconds()
+	return summary
+}
+
+// returns the rules that omitted files, ordered by the number of files they omitted
+func (summary *RunSummary) RulesByCount() []string {
+	var rules []string
+	for rule := range summary.Files.OmittedByRule {
+		rules = append(rules, rule)
+	}
+
+	sort.Slice(rules, func(i, j int) bool {
+		if summary.Files.OmittedByRule[rules[i]] == summary.Files.OmittedByRule[rules[j]] {
+			return rules[i] < rules[j]
+		}
+		return summary.Files.OmittedByRule[rules[i]] > summary.Files.OmittedByRule[rules[j]]
+	})
+
+	return rules
+}
+
+// prints the summary as JSON to stdout with `-output json`. Otherwise, the omitted files per rule are logged
+func PrintRunSummary(summary *RunSummary) {
+	if outputFormat != FormatJSON {
+		log.Info("Kept ", summary.Files.Included, " file(s), and omitted ", summary.Files.Omitted, " file(s):")
+		for _, rule := range summary.RulesByCount() {
+			log.Info("\t- ", rule, ": ", summary.Files.OmittedByRule[rule])
+		}
+		return
+	}
+
+	content, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		log.Error(err)
+		return
+	}
+
+	fmt.Println(string(content))
+}
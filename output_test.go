@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Integration test for `NewRunSummary()` with `./sample-projects/sample-node-project`
+func TestNewRunSummaryWithNodeSample(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+
+	started := time.Now()
+	manifest, err := zipSource("./sample-projects/sample-node-project", filepath.Join(t.TempDir(), "test-output.zip"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	summary := NewRunSummary(manifest, started)
+	if summary.Archive != manifest.Archive || summary.Size == 0 || summary.DurationMs < 0 {
+		t.Errorf("Got: %+v", summary)
+	}
+
+	included, omitted := 0, 0
+	for _, entry := range manifest.Entries {
+		if entry.Included {
+			included++
+		} else {
+			omitted++
+		}
+	}
+
+	if summary.Files.Included != included || summary.Files.Omitted != omitted {
+		t.Errorf("Got: %d included and %d omitted, Expected: %d included and %d omitted",
+			summary.Files.Included, summary.Files.Omitted, included, omitted)
+	}
+
+	countByRules := 0
+	for _, count := range summary.Files.OmittedByRule {
+		countByRules += count
+	}
+
+	if countByRules != omitted || summary.Files.OmittedByRule["test-folders"] == 0 {
+		t.Errorf("Got: %v", summary.Files.OmittedByRule)
+	}
+
+	// the rules that omitted the most files come first
+	rules := summary.RulesByCount()
+	for i := 1; i < len(rules); i++ {
+		if summary.Files.OmittedByRule[rules[i-1]] < summary.Files.OmittedByRule[rules[i]] {
+			t.Errorf("Got: %v", rules)
+		}
+	}
+}
+
+func TestRunSummaryJSON(t *testing.T) {
+	summary := NewRunSummary(&Manifest{Archive: filepath.Join(t.TempDir(), "missing.zip")}, time.Now())
+
+	content, err := json.Marshal(summary)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(content, &fields); err != nil {
+		t.Fatal(err)
+	}
+
+	// the smells are an empty list (and not `null`), and the paths of files that were not written are left out
+	expectedKeys := []string{"archive", "durationMs", "exitCode", "files", "size", "smells", "version"}
+	var keys []string
+	for _, key := range expectedKeys {
+		if _, ok := fields[key]; ok {
+			keys = append(keys, key)
+		}
+	}
+
+	// the archive is empty if it does not exist (e.g. since the partial archive of a failed run was removed)
+	if !reflect.DeepEqual(keys, expectedKeys) || len(fields) != len(expectedKeys) || fields["smells"] == nil || fields["archive"] != "" {
+		t.Errorf("Got: %s", content)
+	}
+}
+
+func TestConfigureOutput(t *testing.T) {
+	defer func() {
+		outputFormat = FormatText
+		log.SetFormatter(&log.TextFormatter{})
+		log.SetLevel(log.FatalLevel)
+	}()
+
+	if err := ConfigureOutput("yaml", FormatText, false); err == nil {
+		t.Errorf("Expected an error for an unknown output")
+	}
+
+	if err := ConfigureOutput(FormatText, "logfmt", false); err == nil {
+		t.Errorf("Expected an error for an unknown log format")
+	}
+
+	if err := ConfigureOutput(FormatText, FormatJSON, true); err != nil {
+		t.Fatal(err)
+	}
+
+	if log.GetLevel() != log.WarnLevel || !ShowsBanner(false) || ShowsBanner(true) {
+		t.Errorf("Got: %v", log.GetLevel())
+	}
+
+	// the indentation of the text logs is not part of the JSON logs
+	content, err := (&trimmedJSONFormatter{}).Format(&log.Entry{Logger: log.StandardLogger(), Message: "\tKept 3 file(s)\n\n", Data: log.Fields{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(content, &entry); err != nil || entry["msg"] != "Kept 3 file(s)" {
+		t.Errorf("Got: %s", content)
+	}
+}
+
+// with `-output json`, stdout only contains the summary - even if the version of the packager (or of its latest
+// release) is unknown
+func TestRunWithJSONOutput(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+	defer log.SetLevel(log.FatalLevel)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"tag_name": "not a version"}`))
+	}))
+	defer server.Close()
+
+	defer func(url string, appVersion string) { latestRelease, AppVersion = url, appVersion }(latestRelease, AppVersion)
+	latestRelease, AppVersion = server.URL, "0.0.0"
+
+	source, err := filepath.Abs("./sample-projects/sample-node-project")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// without a `current_version` in the working directory
+	workingDir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(workingDir)
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(stdout *os.File) { os.Stdout = stdout }(os.Stdout)
+	os.Stdout = writer
+
+	output := make(chan []byte)
+	go func() {
+		content, _ := io.ReadAll(reader)
+		output <- content
+	}()
+
+	exitCode := run([]string{"-quiet", "-source", source, "-target", t.TempDir(), "-output", "json"})
+	writer.Close()
+	content := <-output
+
+	if exitCode != ExitOK {
+		t.Errorf("Got: %d, Expected: %d", exitCode, ExitOK)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	var summary RunSummary
+	if err := decoder.Decode(&summary); err != nil {
+		t.Fatalf("%v: %s", err, content)
+	}
+
+	if err := decoder.Decode(&summary); err != io.EOF {
+		t.Errorf("Expected only the summary on stdout: %s", content)
+	}
+
+	if summary.ExitCode != ExitOK || summary.Archive == "" {
+		t.Errorf("Got: %+v", summary)
+	}
+}
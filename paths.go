@@ -0,0 +1,174 @@
+package main
+
+import (
+	"path"
+	"strings"
+)
+
+// converts a path into the internal representation used by the rules, i.e. with forward slashes only. This is done
+// regardless of the OS the tool runs on, so that e.g. `src\build\some.js` (as produced on Windows, or written into a
+// config) is matched exactly like `src/build/some.js`. The names of the zip entries (and of the manifest) use
+// `filepath.ToSlash` instead, since a backslash is a valid character of a file name on e.g. Linux
+func NormalizePath(p string) string {
+	return strings.ReplaceAll(p, "\\", "/")
+}
+
+// splits a path into its non-empty segments, e.g. `/src/app/build/` (or `\src\app\build\`) becomes `["src", "app", "build"]`
+func PathSegments(p string) []string {
+	return strings.FieldsFunc(NormalizePath(p), func(r rune) bool {
+		return r == '/'
+	})
+}
+
+// returns the last segment of a path, e.g. `some.js` for `/src/some.js` (or "" for an empty path)
+func BaseName(p string) string {
+	segments := PathSegments(p)
+	if len(segments) == 0 {
+		return ""
+	}
+
+	return segments[len(segments)-1]
+}
+
+// check if any segment of the path is exactly one of the provided names. For example, `build` matches `/build`,
+// `/build/some.js` and `/src/build/some.js`, but not `/src/rebuilder.js` or `/building/some.js`
+func HasPathSegment(p string, names ...string) bool {
+	for _, segment := range PathSegments(p) {
+		for _, name := range names {
+			if segment == name {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// check if the path is the provided folder or lies within it. The folder may consist of several segments (like `src/lib`),
+// which then have to appear in this order (and without gaps) somewhere in the path
+func IsInFolder(p string, folder string) bool {
+	pathSegments := PathSegments(p)
+	folderSegments := PathSegments(folder)
+
+	if len(folderSegments) == 0 {
+		return false
+	}
+
+	for i := 0; i+len(folderSegments) <= len(pathSegments); i++ {
+		matches := true
+		for j, folderSegment := range folderSegments {
+			if pathSegments[i+j] != folderSegment {
+				matches = false
+				break
+			}
+		}
+
+		if matches {
+			return true
+		}
+	}
+
+	return false
+}
+
+// check if the path is the provided folder or lies within it, where the folder is anchored at the root (unlike
+// `IsInFolder()`). For example, `/dist` contains `/dist/some.js`, but not `/src/dist/some.js`
+func IsUnder(p string, folder string) bool {
+	pathSegments := PathSegments(p)
+	folderSegments := PathSegments(folder)
+
+	if len(folderSegments) > len(pathSegments) {
+		return false
+	}
+
+	for i, folderSegment := range folderSegments {
+		if pathSegments[i] != folderSegment {
+			return false
+		}
+	}
+
+	return true
+}
+
+// check if the last segment of the path (i.e., the file name) is exactly one of the provided names
+func HasBaseName(p string, names ...string) bool {
+	base := BaseName(p)
+
+	for _, name := range names {
+		if base == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// check if the file name of the path ends with one of the provided extensions (like `.js` or `.spec.ts`)
+func HasExtension(p string, extensions ...string) bool {
+	base := BaseName(p)
+
+	for _, extension := range extensions {
+		if strings.HasSuffix(base, extension) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// same as `HasExtension()`, but compares case-insensitively (so e.g. `logo.PNG` ends with `.png`)
+func HasExtensionFold(p string, extensions ...string) bool {
+	base := strings.ToLower(BaseName(p))
+
+	for _, extension := range extensions {
+		if strings.HasSuffix(base, strings.ToLower(extension)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// check if the path matches the provided glob pattern. The pattern is matched segment by segment from the root of the
+// path, where:
+//   - `*` matches any sequence of characters within a single segment (e.g. `*.spec.js`)
+//   - `?` matches any single character within a single segment
+//   - `**` matches any number of segments (including none), e.g. `src/**/__mocks__/**`
+func MatchGlob(pattern string, p string) bool {
+	return matchSegments(PathSegments(pattern), PathSegments(p))
+}
+
+func matchSegments(patternSegments []string, pathSegments []string) bool {
+	for len(patternSegments) > 0 {
+		if patternSegments[0] == "**" {
+			patternSegments = patternSegments[1:]
+
+			// a trailing `**` matches everything that is left
+			if len(patternSegments) == 0 {
+				return true
+			}
+
+			for i := 0; i <= len(pathSegments); i++ {
+				if matchSegments(patternSegments, pathSegments[i:]) {
+					return true
+				}
+			}
+
+			return false
+		}
+
+		if len(pathSegments) == 0 {
+			return false
+		}
+
+		// NOTE: `path.Match` only fails for malformed patterns, which we simply treat as "no match"
+		if doesMatch, err := path.Match(patternSegments[0], pathSegments[0]); err != nil || !doesMatch {
+			return false
+		}
+
+		patternSegments = patternSegments[1:]
+		pathSegments = pathSegments[1:]
+	}
+
+	return len(pathSegments) == 0
+}
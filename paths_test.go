@@ -0,0 +1,246 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Regression tests for the rules in `utils.go` with "tricky" names, i.e. names that merely contain the name of an omitted
+// folder (like `rebuilder.js` or `publicApi`) and thus must not be omitted
+func TestRulesWithTrickyNames(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+
+	testCases := []struct {
+		name     string
+		rule     func(string) bool
+		path     string
+		expected bool
+	}{
+		{"build folder itself", IsBuildFolder, "/build", true},
+		{"file in build folder", IsBuildFolder, "/build/some.js", true},
+		{"file in nested build folder", IsBuildFolder, "/src/build/some.js", true},
+		{"file containing build", IsBuildFolder, "/src/rebuilder.js", false},
+		{"folder starting with build", IsBuildFolder, "/building/something.js", false},
+		{"dist folder", IsDistFolder, "/dist/public-test.js", true},
+		{"folder starting with dist", IsDistFolder, "/distance/should-be-included.js", false},
+		{"file ending with dist", IsDistFolder, "/src/redist", false},
+		{"public folder", IsPublicFolder, "/public/index.html", true},
+		{"folder starting with public", IsPublicFolder, "/src/publicApi/index.js", false},
+		{"file containing public", IsPublicFolder, "/src/makePublic.js", false},
+		{"idea folder", IsIdeFolder, "/.idea/workspace.xml", true},
+		{"vscode folder", IsIdeFolder, "/.vscode/settings.json", true},
+		{"file containing idea", IsIdeFolder, "/src/.idea-board.js", false},
+		{"file named like an idea", IsIdeFolder, "/src/my.idea", false},
+		{"node_modules folder", IsNodeModules, "/node_modules/express/index.js", true},
+		{"nested node_modules folder", IsNodeModules, "/packages/a/node_modules/x.js", true},
+		{"folder starting with node_modules", IsNodeModules, "/node_modules_patches/x.js", false},
+		{"git folder", IsGitFolder, "/.git/HEAD", true},
+		{"github folder", IsGitFolder, "/.github/workflows/go.yml", false},
+		{"angular cache folder", IsAngularCacheFolder, "/.angular/cache/some.js", true},
+		{"angular source folder", IsAngularCacheFolder, "/src/angular/some.js", false},
+		{"common test folder", IsCommonTestFolder, "/more/test/some-test.js", true},
+		{"e2e folder", IsCommonTestFolder, "/e2e/app.po.ts", true},
+		{"folder ending with test", IsCommonTestFolder, "/attest/some.js", false},
+		{"folder starting with tests", IsCommonTestFolder, "/testimonials-no-tests/should-be-included.js", false},
+		{"test file", IsTestFile, "/src/app.spec.ts", true},
+		{"file containing spec", IsTestFile, "/src/spec.tsx.js", false},
+		{"stylesheet", IsStyleSheet, "/styles/blub.css", true},
+		{"stylesheet-like extension", IsStyleSheet, "/styles/blub.css2", false},
+		{"license file", IsMiscNotRequiredFile, "/LICENSE", true},
+		{"file ending with license", IsMiscNotRequiredFile, "/src/NO_LICENSE", false},
+		{"tsconfig", IsMiscNotRequiredFile, "/tsconfig.json", true},
+		{"file ending with tsconfig", IsMiscNotRequiredFile, "/src/not-a-tsconfig.json", false},
+		{"macos folder", IsMiscNotRequiredFile, "/__MACOSX/some.js", true},
+		{"minified file", IsMinified, "/vendor/jquery.min.js", true},
+		{"file containing min", IsMinified, "/src/admin.js", false},
+	}
+
+	for _, testCase := range testCases {
+		if got := testCase.rule(testCase.path); got != testCase.expected {
+			t.Errorf("%s: got %v for `%s`, expected %v", testCase.name, got, testCase.path, testCase.expected)
+		}
+	}
+}
+
+// Tests for the `-tests` folder matching, which has to respect segment boundaries
+func TestIsInTestFolder(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+
+	testCases := []struct {
+		path       string
+		testsPaths []string
+		expected   bool
+	}{
+		{"/test", []string{"/test"}, true},
+		{"/test/some-test.js", []string{"/test"}, true},
+		{"/more/test/some-test.js", []string{"/test"}, true},
+		{"/attest/some.js", []string{"/test"}, false},
+		{"/test-utils/some.js", []string{"/test"}, false},
+		{"/src/specs/unit/a.js", []string{"/src/specs"}, true},
+		{"/src/specs-old/a.js", []string{"/src/specs"}, false},
+		{"/lib/specs/a.js", []string{"/src/specs"}, false},
+	}
+
+	for _, testCase := range testCases {
+		if got := IsInTestFolder(testCase.path, testCase.testsPaths); got != testCase.expected {
+			t.Errorf("got %v for `%s` with tests path `%s`, expected %v", got, testCase.path, testCase.testsPaths,
+				testCase.expected)
+		}
+	}
+}
+
+// Tests for the glob matching on path segments
+func TestMatchGlob(t *testing.T) {
+	testCases := []struct {
+		pattern  string
+		path     string
+		expected bool
+	}{
+		{"src/*.js", "/src/app.js", true},
+		{"src/*.js", "/src/lib/app.js", false},
+		{"src/**/*.js", "/src/app.js", true},
+		{"src/**/*.js", "/src/lib/deep/app.js", true},
+		{"**/__mocks__/**", "/src/a/__mocks__/fs.js", true},
+		{"**/__mocks__/**", "/src/a/__mocks__", true},
+		{"**/__mocks__/**", "/src/a/not__mocks__/fs.js", false},
+		{"spec/**", "/spec/a.js", true},
+		{"spec/**", "/src/spec/a.js", false},
+		{"**/build", "/src/rebuild", false},
+		{"src/?.js", "/src/a.js", true},
+		{"src/?.js", "/src/ab.js", false},
+		{"src/[.js", "/src/[.js", false},
+	}
+
+	for _, testCase := range testCases {
+		if got := MatchGlob(testCase.pattern, testCase.path); got != testCase.expected {
+			t.Errorf("got %v for pattern `%s` and `%s`, expected %v", got, testCase.pattern, testCase.path, testCase.expected)
+		}
+	}
+}
+
+// The rules have to behave the same regardless of the OS the archive is built on. Thus, we check them against
+// Windows-style paths (which is possible on any OS since all paths are normalized to forward slashes)
+func TestRulesWithWindowsPaths(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+
+	testCases := []struct {
+		path       string
+		testsPaths []string
+		expected   bool
+	}{
+		{`\app.js`, nil, true},
+		{`\src\rebuilder.js`, nil, true},
+		{`\src\publicApi\index.js`, nil, true},
+		{`\build\some.js`, nil, false},
+		{`\node_modules\express\index.js`, nil, false},
+		{`\src\.idea\workspace.xml`, nil, false},
+		{`\e2e\app.e2e-spec.ts`, nil, false},
+		{`\styles\blub.css`, nil, false},
+		{`\more\test\some-test.js`, []string{"/test"}, false},
+		{`\more\tests\some-test.js`, []string{"/test"}, true},
+		{`\src\specs\a.js`, []string{`/src\specs`}, false},
+		{`\bower_components\bower.json`, nil, true},
+	}
+
+	for _, testCase := range testCases {
+		if got := isRequired(testCase.path, testCase.testsPaths); got != testCase.expected {
+			t.Errorf("`isRequired()` returned %v for `%s`, expected %v", got, testCase.path, testCase.expected)
+		}
+
+		// the forward slash version of the same path has to lead to the same result
+		if got := isRequired(NormalizePath(testCase.path), testCase.testsPaths); got != testCase.expected {
+			t.Errorf("`isRequired()` returned %v for `%s`, expected %v", got, NormalizePath(testCase.path), testCase.expected)
+		}
+	}
+
+	if !CheckIfSCAFileExists(`C:\my-app\package-lock.json`) || CheckIfSCAFileExists(`C:\my-app\bower_components\x\yarn.lock`) {
+		t.Error("`CheckIfSCAFileExists()` does not handle Windows-style paths")
+	}
+}
+
+// Extension rules compare case-insensitively by default, can be made case-sensitive per rule, and leave a note in the
+// decision whenever a path only matched via case folding
+func TestExtensionRulesCaseFolding(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+
+	for _, path := range []string{"/logo.PNG", "/report.PDF", "/db.accdb", "/db.ACCDB", "/fonts/a.WOFF2", "/vendor/jquery.MIN.js"} {
+		if isRequired(path, nil) {
+			t.Errorf("`%s` should have been omitted", path)
+		}
+	}
+
+	decision := evaluatePath("/logo.PNG", nil)
+	if decision.Rule != "images" || len(decision.Notes) != 1 {
+		t.Errorf("expected a case folding note for `/logo.PNG`, got %+v", decision)
+	}
+
+	if decision := evaluatePath("/logo.png", nil); len(decision.Notes) != 0 {
+		t.Errorf("expected no case folding note for `/logo.png`, got %+v", decision)
+	}
+
+	if err := SetCaseSensitiveExtensionRules("images, documents"); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		imageRule.CaseSensitive = false
+		documentRule.CaseSensitive = false
+	}()
+
+	if !isRequired("/logo.PNG", nil) || !isRequired("/report.PDF", nil) || isRequired("/logo.png", nil) {
+		t.Error("case-sensitive rules should only match the exact extensions")
+	}
+
+	// the extensions of Access are upper case (like before extensions were compared case-insensitively)
+	if isRequired("/db.ACCDB", nil) || !isRequired("/db.accdb", nil) {
+		t.Error("case-sensitive rules should keep the original spelling of the extensions")
+	}
+
+	if err := SetCaseSensitiveExtensionRules("pictures"); err == nil {
+		t.Error("expected an error for an unknown rule name")
+	}
+}
+
+// On e.g. Linux, a backslash is a valid character of a file name. The zip entry (and the manifest) have to keep it, since
+// folding it into a `/` would create a path that does not exist in the source
+func TestZipSourceKeepsBackslashesInNames(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("a backslash is a path separator on Windows")
+	}
+
+	log.SetLevel(log.FatalLevel)
+
+	source := writeTestFiles(t, map[string]string{`a\b.js`: "console.log('app')", "app.js": "console.log('app')"})
+
+	target := filepath.Join(t.TempDir(), "test-output.zip")
+	manifest, err := zipSource(source, target, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var paths []string
+	for _, entry := range manifest.Entries {
+		paths = append(paths, entry.Path)
+	}
+	sort.Strings(paths)
+
+	expected := []string{`a\b.js`, "app.js"}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("Got: %v, Expected: %v", paths, expected)
+	}
+
+	zipReader := readZip(target)
+	var zipFiles []string
+	for _, file := range zipReader.File {
+		zipFiles = append(zipFiles, file.Name)
+	}
+	sort.Strings(zipFiles)
+
+	if !reflect.DeepEqual(zipFiles, expected) {
+		t.Errorf("Got: %v, Expected: %v", zipFiles, expected)
+	}
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// what the archive is packaged for (set via `-mode`)
+const (
+	// the full upload to the Veracode Platform (policy and sandbox scans)
+	ModePlatform = "platform"
+	// the Veracode Pipeline Scan, which has a stricter size limit and only needs the code
+	ModePipeline = "pipeline"
+)
+
+var packagingMode string = ModePlatform
+
+// the maximum size of an archive the Pipeline Scan accepts
+const pipelineScanMaxSize int64 = 200 * 1024 * 1024
+
+// the prefix of the name of the output zip (e.g. `vc-output_2023-Jan-04.zip`)
+const outputZipPrefix = "vc-output_"
+
+// the prefix of the name of the zip for the Pipeline Scan (e.g. `vc-output-pipeline_2023-Jan-04.zip`)
+const pipelineZipPrefix = "vc-output-pipeline_"
+
+// the suffix of the metadata that is written next to the zip for the Pipeline Scan
+const pipelineMetadataSuffix = ".pipeline.json"
+
+// returned if the archive exceeds the size limit of the Pipeline Scan
+var ErrPipelineSizeExceeded = errors.New("the archive is too large for the Pipeline Scan")
+
+// the number of entries listed in the breakdown of an archive that is too large
+const pipelineBreakdownSize = 10
+
+// the extensions of the files the Pipeline Scan analyzes. Everything else is omitted in the `pipeline` mode
+var pipelineScanExtensions = append([]string{
+	".js", ".jsx", ".mjs", ".cjs", ".ts", ".tsx", ".mts", ".cts", ".vue", ".svelte", ".astro",
+}, templateExtensions...)
+
+// sets what the archive is packaged for
+func SetPackagingMode(mode string) error {
+	if mode != ModePlatform && mode != ModePipeline {
+		return fmt.Errorf("unknown mode `%s` (expected `%s` or `%s`)", mode, ModePlatform, ModePipeline)
+	}
+
+	packagingMode = mode
+	return nil
+}
+
+// returns the prefix of the name of the output zip for the packaging mode
+func GetOutputZipPrefix() string {
+	if packagingMode == ModePipeline {
+		return pipelineZipPrefix
+	}
+
+	return outputZipPrefix
+}
+
+// check if the file is omitted because the Pipeline Scan does not analyze it (like a `.json` or `.md` file). Only
+// applies in the `pipeline` mode
+func IsOmittedInPipelineMode(path string) bool {
+	if packagingMode != ModePipeline || strings.HasSuffix(path, "/") {
+		return false
+	}
+
+	return !HasExtensionFold(path, pipelineScanExtensions...)
+}
+
+// an entry of the breakdown of an archive, i.e. a file or a top-level folder and its (compressed) size
+type ArchiveSizeEntry struct {
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+}
+
+// returns the largest files and top-level folders of the archive (by compressed size), as well as its total size
+func GetArchiveBreakdown(archivePath string, limit int) ([]ArchiveSizeEntry, []ArchiveSizeEntry, int64, error) {
+	info, err := os.Stat(archivePath)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	reader, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	defer reader.Close()
+
+	var files []ArchiveSizeEntry
+	folderSizes := map[string]int64{}
+	for _, file := range reader.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+
+		size := int64(file.CompressedSize64)
+		files = append(files, ArchiveSizeEntry{Name: file.Name, Size: size})
+
+		folder := "(root)"
+		if segments := PathSegments(file.Name); len(segments) > 1 {
+			folder = segments[0] + "/"
+		}
+		folderSizes[folder] += size
+	}
+
+	var folders []ArchiveSizeEntry
+	for folder, size := range folderSizes {
+		folders = append(folders, ArchiveSizeEntry{Name: folder, Size: size})
+	}
+
+	for _, entries := range [][]ArchiveSizeEntry{files, folders} {
+		sort.Slice(entries, func(i, j int) bool {
+			if entries[i].Size == entries[j].Size {
+				return entries[i].Name < entries[j].Name
+			}
+			return entries[i].Size > entries[j].Size
+		})
+	}
+
+	if len(files) > limit {
+		files = files[:limit]
+	}
+
+	if len(folders) > limit {
+		folders = folders[:limit]
+	}
+
+	return files, folders, info.Size(), nil
+}
+
+// returns e.g. `1.5 MB`
+func FormatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	value := float64(size)
+	for _, suffix := range []string{"KB", "MB", "GB"} {
+		value /= unit
+		if value < unit {
+			return fmt.Sprintf("%.1f %s", value, suffix)
+		}
+	}
+
+	return fmt.Sprintf("%.1f TB", value/unit)
+}
+
+// checks that the archive does not exceed the size limit of the Pipeline Scan. Otherwise, the largest files and
+// top-level folders are logged, and an error is returned
+func CheckPipelineArchiveSize(archivePath string) error {
+	files, folders, size, err := GetArchiveBreakdown(archivePath, pipelineBreakdownSize)
+	if err != nil {
+		return err
+	}
+
+	if size <= pipelineScanMaxSize {
+		log.Info("\tThe archive has ", FormatSize(size), " (the Pipeline Scan accepts up to ", FormatSize(pipelineScanMaxSize), ")")
+		return nil
+	}
+
+	log.Error("\tThe archive has ", FormatSize(size), ", which exceeds the limit of the Pipeline Scan (", FormatSize(pipelineScanMaxSize), ")")
+	log.Error("\tThe largest top-level folders are:")
+	for _, folder := range folders {
+		log.Error("\t\t- ", folder.Name, " (", FormatSize(folder.Size), ")")
+	}
+
+	log.Error("\tThe largest files are:")
+	for _, file := range files {
+		log.Error("\t\t- ", file.Name, " (", FormatSize(file.Size), ")")
+	}
+
+	log.Error("\tConsider omitting some of them via `-tests` (or by packaging a narrower `-source`)")
+	return fmt.Errorf("%w: the archive has %s, but the limit is %s", ErrPipelineSizeExceeded, FormatSize(size), FormatSize(pipelineScanMaxSize))
+}
+
+// the metadata for the Pipeline Scan step, which contains the arguments to pass to the Pipeline Scan
+type PipelineMetadata struct {
+	File        string `json:"file"`
+	Size        int64  `json:"size"`
+	ProjectName string `json:"projectName"`
+	ProjectRef  string `json:"projectRef,omitempty"`
+	// the arguments for `pipeline-scan.jar`, e.g. `["--file", "vc-output-pipeline_2023-Jan-04.zip", ...]`
+	Arguments []string `json:"arguments"`
+}
+
+// returns the path of the metadata for a zip for the Pipeline Scan, e.g. `vc-output-pipeline_2023-Jan-04.pipeline.json`
+func GetPipelineMetadataPath(zipPath string) string {
+	return strings.TrimSuffix(zipPath, ".zip") + pipelineMetadataSuffix
+}
+
+// writes the metadata for the Pipeline Scan step next to the zip, and returns its path
+func WritePipelineMetadata(source string, archivePath string) (string, error) {
+	info, err := os.Stat(archivePath)
+	if err != nil {
+		return "", err
+	}
+
+	metadata := PipelineMetadata{
+		File:        archivePath,
+		Size:        info.Size(),
+		ProjectName: GetAppName(source),
+		ProjectRef:  GetGitBranch(source),
+	}
+
+	metadata.Arguments = []string{"--file", metadata.File, "--project_name", metadata.ProjectName}
+	if metadata.ProjectRef != "" {
+		metadata.Arguments = append(metadata.Arguments, "--project_ref", metadata.ProjectRef)
+	}
+
+	content, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	metadataPath := GetPipelineMetadataPath(archivePath)
+	return metadataPath, os.WriteFile(metadataPath, content, 0644)
+}
+
+// returns the name of the app, i.e. the `name` in its `package.json` (or the name of its folder)
+func GetAppName(source string) string {
+	var packageJson struct {
+		Name string `json:"name"`
+	}
+
+	if content, err := os.ReadFile(filepath.Join(source, "package.json")); err == nil {
+		if json.Unmarshal(content, &packageJson) == nil && packageJson.Name != "" {
+			return packageJson.Name
+		}
+	}
+
+	if absolutePath, err := filepath.Abs(source); err == nil {
+		return filepath.Base(absolutePath)
+	}
+
+	return filepath.Base(source)
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func TestSetPackagingMode(t *testing.T) {
+	defer func() { packagingMode = ModePlatform }()
+
+	if err := SetPackagingMode(ModePipeline); err != nil || packagingMode != ModePipeline {
+		t.Errorf("Got: %v, %s", err, packagingMode)
+	}
+
+	if err := SetPackagingMode("policy"); err == nil {
+		t.Errorf("Expected an error for an unknown mode")
+	}
+}
+
+func TestIsOmittedInPipelineMode(t *testing.T) {
+	defer func() { packagingMode = ModePlatform }()
+
+	paths := map[string]bool{
+		"/src/app.js":            false,
+		"/src/App.vue":           false,
+		"/src/main.TS":           false,
+		"/views/index.ejs":       false,
+		"/src/":                  false,
+		"/package.json":          true,
+		"/package-lock.json":     true,
+		"/README.md":             true,
+		"/src/config.yaml":       true,
+		"/src/locales/de.json":   true,
+		"/src/assets/logo.svg":   true,
+		"/scripts/deploy.sh":     true,
+		"/src/components/Form.d": true,
+	}
+
+	for path, expected := range paths {
+		if IsOmittedInPipelineMode(path) {
+			t.Errorf("%s is omitted in the `platform` mode", path)
+		}
+
+		packagingMode = ModePipeline
+		if got := IsOmittedInPipelineMode(path); got != expected {
+			t.Errorf("%s: Got: %v, Expected: %v", path, got, expected)
+		}
+		packagingMode = ModePlatform
+	}
+}
+
+// Integration test for `zipSource()` in the `pipeline` mode with `./sample-projects/sample-node-project`
+func TestPipelineModeWithNodeSample(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+
+	packagingMode = ModePipeline
+	defer func() { packagingMode = ModePlatform }()
+
+	zipFileContents := generateZipAndReturnItsFiles("./sample-projects/sample-node-project",
+		filepath.Join(t.TempDir(), "test-output.zip"), nil)
+
+	for _, name := range zipFileContents {
+		if !HasExtensionFold(name, pipelineScanExtensions...) {
+			t.Errorf("%s is part of the zip for the Pipeline Scan", name)
+		}
+	}
+
+	var expected []string
+	for _, name := range generateZipAndReturnItsFiles("./sample-projects/sample-node-project",
+		filepath.Join(t.TempDir(), "test-output-platform.zip"), nil) {
+		if HasExtensionFold(name, pipelineScanExtensions...) {
+			expected = append(expected, name)
+		}
+	}
+
+	if len(expected) == 0 || !reflect.DeepEqual(zipFileContents, expected) {
+		t.Errorf("Got: %v", zipFileContents)
+		t.Errorf("Expected: %v", expected)
+	}
+}
+
+// writes a zip with the provided files (and their sizes) and returns its path
+func writeTestArchive(t *testing.T, files map[string]int) string {
+	archivePath := filepath.Join(t.TempDir(), "test-output.zip")
+	f, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	writer := zip.NewWriter(f)
+	for name, size := range files {
+		// stored, so that the compressed size is the size of the file
+		fileWriter, err := writer.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := fileWriter.Write([]byte(strings.Repeat("a", size))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return archivePath
+}
+
+func TestGetArchiveBreakdown(t *testing.T) {
+	archivePath := writeTestArchive(t, map[string]int{
+		"src/app.js":         300,
+		"src/utils.js":       100,
+		"lib/big.js":         500,
+		"lib/small.js":       10,
+		"server.js":          50,
+		"views/index.ejs":    20,
+		"views/partials.ejs": 20,
+	})
+
+	files, folders, size, err := GetArchiveBreakdown(archivePath, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedFiles := []ArchiveSizeEntry{{"lib/big.js", 500}, {"src/app.js", 300}, {"src/utils.js", 100}}
+	if !reflect.DeepEqual(files, expectedFiles) {
+		t.Errorf("Got: %v", files)
+		t.Errorf("Expected: %v", expectedFiles)
+	}
+
+	expectedFolders := []ArchiveSizeEntry{{"lib/", 510}, {"src/", 400}, {"(root)", 50}}
+	if !reflect.DeepEqual(folders, expectedFolders) {
+		t.Errorf("Got: %v", folders)
+		t.Errorf("Expected: %v", expectedFolders)
+	}
+
+	if info, _ := os.Stat(archivePath); size != info.Size() {
+		t.Errorf("Got: %d, Expected: %d", size, info.Size())
+	}
+
+	// a small archive fits into the Pipeline Scan
+	log.SetLevel(log.FatalLevel)
+	if err := CheckPipelineArchiveSize(archivePath); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	sizes := map[int64]string{
+		512:                    "512 B",
+		1536:                   "1.5 KB",
+		pipelineScanMaxSize:    "200.0 MB",
+		3 * 1024 * 1024 * 1024: "3.0 GB",
+	}
+
+	for size, expected := range sizes {
+		if got := FormatSize(size); got != expected {
+			t.Errorf("Got: %s, Expected: %s", got, expected)
+		}
+	}
+}
+
+func TestWritePipelineMetadata(t *testing.T) {
+	source := writeTestFiles(t, map[string]string{
+		"package.json": `{"name": "my-shop", "version": "1.0.0"}`,
+		".git/HEAD":    "ref: refs/heads/feature/checkout\n",
+	})
+
+	archivePath := writeTestArchive(t, map[string]int{"app.js": 10})
+	metadataPath, err := WritePipelineMetadata(source, archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if metadataPath != strings.TrimSuffix(archivePath, ".zip")+".pipeline.json" {
+		t.Errorf("Got: %s", metadataPath)
+	}
+
+	content, err := os.ReadFile(metadataPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var metadata PipelineMetadata
+	if err := json.Unmarshal(content, &metadata); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedArguments := []string{"--file", archivePath, "--project_name", "my-shop", "--project_ref", "feature/checkout"}
+	if metadata.ProjectName != "my-shop" || metadata.ProjectRef != "feature/checkout" || metadata.Size == 0 ||
+		!reflect.DeepEqual(metadata.Arguments, expectedArguments) {
+		t.Errorf("Got: %+v", metadata)
+	}
+}
+
+func TestGetAppNameAndGitBranch(t *testing.T) {
+	repository := t.TempDir()
+	source := filepath.Join(repository, "frontend")
+	if err := os.MkdirAll(filepath.Join(repository, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.MkdirAll(source, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// a detached HEAD has no branch
+	if err := os.WriteFile(filepath.Join(repository, ".git", "HEAD"), []byte("4f1c2a9e\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// without a `package.json`, the name of the folder is used
+	got := []string{GetAppName(source), GetGitBranch(source)}
+	expected := []string{"frontend", ""}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Got: %v", got)
+		t.Errorf("Expected: %v", expected)
+	}
+}
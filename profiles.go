@@ -0,0 +1,311 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// a packaging profile tailors the rules to the framework that is used by the JavaScript app
+type Profile struct {
+	Name        string
+	Description string
+	// the profile is detected if one of these packages is a (dev) dependency in the `package.json` of the source...
+	Dependencies []string
+	// ... or if one of these config files exists in the root of the source (may contain globs like `next.config.*`)
+	ConfigFiles []string
+	// folders that only contain generated output or caches of the framework (and are thus omitted)
+	OmittedFolders []string
+	// folders that only contain generated output if they lie in the root of the source or of a package (i.e. next to a
+	// `package.json`), like `coverage`. Elsewhere, they may be first-party code (like `src/coverage/`)
+	PackageRootFolders []string
+	// files that only contain framework config noise (and are thus omitted)
+	OmittedFiles []string
+	// globs (relative to the source) of first-party code that must not be omitted by the `build`, `dist` and `public` rules,
+	// e.g. `app/**` for the routes of a Next.js app (where a route like `app/build/page.tsx` is perfectly valid)
+	KeptPaths []string
+	// if `true`, hand-written scripts in `public` (like service workers) are kept and flagged instead of being dropped
+	FlagPublicScripts bool
+}
+
+var angularProfile = &Profile{
+	Name:               "angular",
+	Description:        "Angular apps",
+	Dependencies:       []string{"@angular/core"},
+	ConfigFiles:        []string{"angular.json", ".angular-cli.json"},
+	OmittedFolders:     []string{".angular"},
+	PackageRootFolders: []string{"coverage", "storybook-static"},
+	OmittedFiles:       []string{".angular-cli.json"},
+}
+
+var nextProfile = &Profile{
+	Name:               "next",
+	Description:        "Next.js apps",
+	Dependencies:       []string{"next"},
+	ConfigFiles:        []string{"next.config.*"},
+	OmittedFolders:     []string{".next", ".vercel", ".turbo"},
+	PackageRootFolders: []string{"coverage", "storybook-static"},
+	KeptPaths: []string{
+		"pages/**", "src/pages/**", "app/**", "src/app/**", "server/**", "src/server/**",
+		"middleware.ts", "middleware.js", "src/middleware.ts", "src/middleware.js",
+	},
+	FlagPublicScripts: true,
+}
+
+var nuxtProfile = &Profile{
+	Name:               "nuxt",
+	Description:        "Nuxt apps",
+	Dependencies:       []string{"nuxt", "nuxt3"},
+	ConfigFiles:        []string{"nuxt.config.*"},
+	OmittedFolders:     []string{".nuxt", ".vercel"},
+	PackageRootFolders: []string{".output", "coverage", "storybook-static"},
+	KeptPaths: []string{
+		"pages/**", "server/**", "middleware/**", "plugins/**", "composables/**", "layouts/**", "components/**",
+		"app.vue", "error.vue",
+	},
+	FlagPublicScripts: true,
+}
+
+var reactProfile = &Profile{
+	Name:               "react",
+	Description:        "React apps (e.g. created via `create-react-app` or Vite)",
+	Dependencies:       []string{"react", "react-dom", "react-scripts"},
+	PackageRootFolders: []string{"coverage", "storybook-static"},
+}
+
+var vueProfile = &Profile{
+	Name:               "vue",
+	Description:        "Vue apps (e.g. created via the Vue CLI or Vite)",
+	Dependencies:       []string{"vue", "@vue/cli-service"},
+	ConfigFiles:        []string{"vue.config.js"},
+	PackageRootFolders: []string{"coverage", "storybook-static"},
+}
+
+var svelteProfile = &Profile{
+	Name:               "svelte",
+	Description:        "Svelte and SvelteKit apps",
+	Dependencies:       []string{"svelte", "@sveltejs/kit"},
+	ConfigFiles:        []string{"svelte.config.js"},
+	OmittedFolders:     []string{".svelte-kit"},
+	PackageRootFolders: []string{"coverage", "storybook-static"},
+}
+
+var nodeProfile = &Profile{
+	Name:               "node",
+	Description:        "Node.js backends (like Express or NestJS apps)",
+	Dependencies:       []string{"express", "koa", "fastify", "@hapi/hapi", "hapi", "@nestjs/core"},
+	PackageRootFolders: []string{"coverage"},
+}
+
+// used if no other profile was detected. Omits the generated output of all frameworks we know
+var genericProfile = &Profile{
+	Name:               "generic",
+	Description:        "Any JavaScript/TypeScript app (used if no framework was detected)",
+	OmittedFolders:     []string{".next", ".nuxt", ".svelte-kit"},
+	PackageRootFolders: []string{".output", "storybook-static", "coverage"},
+}
+
+// all profiles, in the order in which they are detected (i.e., more specific profiles have to come first)
+var profiles = []*Profile{
+	angularProfile, nextProfile, nuxtProfile, svelteProfile, vueProfile, reactProfile, nodeProfile, genericProfile,
+}
+
+// the profile that is used for packaging (set via `-profile` or detected automatically)
+var activeProfile *Profile = genericProfile
+
+var didPrintProfileMsg bool = false
+
+// returns the profile with the provided name (or `nil` if there is none)
+func GetProfile(name string) *Profile {
+	for _, profile := range profiles {
+		if profile.Name == name {
+			return profile
+		}
+	}
+
+	return nil
+}
+
+// returns the names of all profiles, e.g. for the built-in help
+func GetProfileNames() []string {
+	var names []string
+	for _, profile := range profiles {
+		names = append(names, profile.Name)
+	}
+
+	return names
+}
+
+// detects the profile of the app in `source`, and returns it along with the reasons why it was chosen
+func DetectProfile(source string) (*Profile, []string) {
+	dependencies := readPackageJsonDependencies(source)
+
+	for _, profile := range profiles {
+		var reasons []string
+
+		for _, dependency := range profile.Dependencies {
+			if _, ok := dependencies[dependency]; ok {
+				reasons = append(reasons, fmt.Sprintf("found the dependency `%s` in `package.json`", dependency))
+			}
+		}
+
+		for _, configFile := range profile.ConfigFiles {
+			matches, err := filepath.Glob(filepath.Join(source, configFile))
+			if err == nil && len(matches) > 0 {
+				reasons = append(reasons, fmt.Sprintf("found the config file `%s`", filepath.Base(matches[0])))
+			}
+		}
+
+		if len(reasons) > 0 {
+			return profile, reasons
+		}
+	}
+
+	return genericProfile, []string{"no framework was detected"}
+}
+
+// returns the `dependencies` and `devDependencies` of the `package.json` in the root of `source` (or an empty map if
+// there is no such `package.json`)
+func readPackageJsonDependencies(source string) map[string]string {
+	dependencies := map[string]string{}
+
+	content, err := os.ReadFile(filepath.Join(source, "package.json"))
+	if err != nil {
+		return dependencies
+	}
+
+	var packageJson struct {
+		Dependencies    map[string]string `json:"dependencies"`
+		DevDependencies map[string]string `json:"devDependencies"`
+	}
+
+	if err := json.Unmarshal(content, &packageJson); err != nil {
+		log.Warn("\tCould not parse `package.json`: ", err)
+		return dependencies
+	}
+
+	for name, version := range packageJson.DevDependencies {
+		dependencies[name] = version
+	}
+
+	for name, version := range packageJson.Dependencies {
+		dependencies[name] = version
+	}
+
+	return dependencies
+}
+
+// sets the profile to use for packaging. If `name` is empty, the profile is detected automatically
+func SetActiveProfile(source string, name string) ([]string, error) {
+	if name == "" {
+		profile, reasons := DetectProfile(source)
+		activeProfile = profile
+		return reasons, nil
+	}
+
+	profile := GetProfile(name)
+	if profile == nil {
+		return nil, fmt.Errorf("unknown profile `%s` (available profiles: %s)", name, strings.Join(GetProfileNames(), ", "))
+	}
+
+	activeProfile = profile
+	return []string{"forced via `-profile`"}, nil
+}
+
+// check if the path is first-party code that the profile keeps, even if it e.g. lies in a folder called `build`
+func (profile *Profile) Keeps(path string) bool {
+	for _, keptPath := range profile.KeptPaths {
+		if MatchGlob(keptPath, path) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// check for the generated output and config noise of the framework of the active profile
+func IsOmittedByProfile(path string) bool {
+	if HasPathSegment(path, activeProfile.OmittedFolders...) || HasBaseName(path, activeProfile.OmittedFiles...) ||
+		IsInPackageRootFolder(path, activeProfile.PackageRootFolders...) {
+		if !didPrintProfileMsg {
+			log.Info("\tIgnoring the generated output of the `" + activeProfile.Name + "` profile (such as `coverage`)")
+			didPrintProfileMsg = true
+		}
+
+		return true
+	}
+
+	return false
+}
+
+// check if the path lies in one of the folders in the root of the source, or in the root of a package of it (like
+// `packages/web/coverage/`). The `package.json` of a package is only looked up if the source is known
+func IsInPackageRootFolder(path string, names ...string) bool {
+	segments := PathSegments(path)
+	for i, segment := range segments {
+		// the folder itself has to be a folder (and not e.g. a file called `coverage`)
+		if i == len(segments)-1 && !strings.HasSuffix(path, "/") {
+			break
+		}
+
+		for _, name := range names {
+			if segment != name {
+				continue
+			}
+
+			if i == 0 {
+				return true
+			}
+
+			if sourceRoot != "" {
+				packageJson := filepath.Join(sourceRoot, filepath.FromSlash(strings.Join(segments[:i], "/")), "package.json")
+				if _, err := os.Stat(packageJson); err == nil {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+// prints the profile and the reasons why it was chosen (used by the `inspect` subcommand)
+func PrintProfile(profile *Profile, reasons []string) {
+	fmt.Printf("Profile: %s - %s\n", profile.Name, profile.Description)
+
+	fmt.Println("Chosen because:")
+	for _, reason := range reasons {
+		fmt.Printf("\t- %s\n", reason)
+	}
+
+	omittedFolders := append([]string{}, profile.OmittedFolders...)
+	sort.Strings(omittedFolders)
+	fmt.Printf("Additionally omitted folders: %s\n", formatList(omittedFolders))
+
+	packageRootFolders := append([]string{}, profile.PackageRootFolders...)
+	sort.Strings(packageRootFolders)
+	fmt.Printf("Additionally omitted folders (in the root of the app or of a package): %s\n", formatList(packageRootFolders))
+
+	omittedFiles := append([]string{}, profile.OmittedFiles...)
+	sort.Strings(omittedFiles)
+	fmt.Printf("Additionally omitted files: %s\n", formatList(omittedFiles))
+	fmt.Printf("Always kept (first-party code): %s\n", formatList(profile.KeptPaths))
+
+	if profile.FlagPublicScripts {
+		fmt.Println("Hand-written scripts in `public` are kept and flagged")
+	}
+}
+
+// formats a list like `a`, `b` (or "-" if the list is empty)
+func formatList(elements []string) string {
+	if len(elements) == 0 {
+		return "-"
+	}
+
+	return "`" + strings.Join(elements, "`, `") + "`"
+}
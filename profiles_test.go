@@ -0,0 +1,142 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Tests that the profiles of the sample projects are detected correctly
+func TestDetectProfileWithSamples(t *testing.T) {
+	testCases := map[string]string{
+		"./sample-projects/sample-node-project":    "node",
+		"./sample-projects/sample-angular-project": "angular",
+		"./sample-projects/sample-react-project":   "react",
+		"./sample-projects/sample-vue-project":     "vue",
+		"./sample-projects":                        "generic",
+	}
+
+	for source, expected := range testCases {
+		profile, reasons := DetectProfile(source)
+		if profile.Name != expected {
+			t.Errorf("detected the `%s` profile for `%s`, expected `%s`", profile.Name, source, expected)
+		}
+
+		if len(reasons) == 0 {
+			t.Errorf("no reasons were provided for the `%s` profile of `%s`", profile.Name, source)
+		}
+	}
+}
+
+// Tests that only the generated output of the active profile is omitted
+func TestProfileRules(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+	defer func() { activeProfile = genericProfile }()
+
+	if _, err := SetActiveProfile("", "angular"); err != nil {
+		t.Fatal(err)
+	}
+
+	if isRequired("/coverage/lcov-report/index.js", nil) || isRequired("/.angular/cache/x.js", nil) {
+		t.Error("the generated output of the `angular` profile should be omitted")
+	}
+
+	if decision := evaluatePath("/coverage/lcov.info", nil); decision.Rule != "profile:angular" {
+		t.Errorf("expected `/coverage/lcov.info` to be omitted by the profile, got %+v", decision)
+	}
+
+	if !isRequired("/src/coverage-report.js", nil) {
+		t.Error("`/src/coverage-report.js` should be required")
+	}
+
+	// `coverage` is only generated output in the root of the app or of a package (i.e. next to a `package.json`)
+	sourceRoot = writeTestFiles(t, map[string]string{"packages/web/package.json": "{}"})
+	defer func() { sourceRoot = "" }()
+
+	pathsToRequired := map[string]bool{
+		"/coverage/":              false,
+		"/packages/web/coverage/": false,
+		"/packages/web/coverage/lcov-report/index.js":  false,
+		"/src/coverage/CoverageChart.tsx":              true,
+		"/packages/web/src/coverage/CoverageChart.tsx": true,
+		"/packages/coverage/index.js":                  true,
+		"/src/storybook-static/index.js":               true,
+	}
+
+	for path, expected := range pathsToRequired {
+		if got := isRequired(path, nil); got != expected {
+			t.Errorf("%s: Got: %v, Expected: %v", path, got, expected)
+		}
+	}
+
+	if _, err := SetActiveProfile("", "does-not-exist"); err == nil {
+		t.Error("expected an error for an unknown profile")
+	}
+}
+
+// Tests the detection of the SSR frameworks (which have to win over the `react`/`vue` profiles)
+func TestDetectSSRProfiles(t *testing.T) {
+	testCases := map[string]string{
+		`{"dependencies": {"next": "13.0.0", "react": "18.2.0"}}`: "next",
+		`{"devDependencies": {"nuxt": "3.0.0", "vue": "3.2.0"}}`:  "nuxt",
+	}
+
+	for packageJson, expected := range testCases {
+		source := writeTestFiles(t, map[string]string{"package.json": packageJson})
+
+		if profile, _ := DetectProfile(source); profile.Name != expected {
+			t.Errorf("detected the `%s` profile for `%s`, expected `%s`", profile.Name, packageJson, expected)
+		}
+	}
+
+	// the config file alone is enough to detect the profile
+	source := t.TempDir()
+	if err := os.WriteFile(filepath.Join(source, "next.config.mjs"), []byte("export default {}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if profile, _ := DetectProfile(source); profile.Name != "next" {
+		t.Errorf("detected the `%s` profile for a `next.config.mjs`, expected `next`", profile.Name)
+	}
+}
+
+// Tests that the Next.js profile keeps routes and server code, drops build caches and flags scripts in `public`
+func TestNextProfileRules(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+	defer func() { activeProfile = genericProfile }()
+
+	if _, err := SetActiveProfile("", "next"); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		path     string
+		expected bool
+	}{
+		{"/app/build/page.tsx", true},
+		{"/app/api/users/route.ts", true},
+		{"/pages/dist/index.js", true},
+		{"/src/pages/api/public/hello.ts", true},
+		{"/server/build/handler.js", true},
+		{"/middleware.ts", true},
+		{"/.next/server/pages/index.js", false},
+		{"/.vercel/output/config.json", false},
+		{"/lib/build/helper.js", false},
+		{"/public/sw.js", true},
+		{"/public/vendor/jquery.min.js", false},
+		{"/public/logo.png", false},
+		{"/app/page.spec.tsx", false},
+	}
+
+	for _, testCase := range testCases {
+		if got := isRequired(testCase.path, nil); got != testCase.expected {
+			t.Errorf("`isRequired()` returned %v for `%s`, expected %v", got, testCase.path, testCase.expected)
+		}
+	}
+
+	if decision := evaluatePath("/public/sw.js", nil); len(decision.Notes) == 0 {
+		t.Error("expected `/public/sw.js` to be flagged")
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// the root of the source that is currently packaged. Rules that look at the content of a file (and not only at its path)
+// use it to read the file. If it is empty, these rules only look at the path
+var sourceRoot string = ""
+
+// how a file in the `public` folder was classified
+const (
+	PublicFileMinified    = "minified bundle"
+	PublicFileVendored    = "vendored library"
+	PublicFileHandWritten = "hand-written source"
+	PublicFileAsset       = "asset"
+)
+
+// matches `<script>` blocks, capturing their attributes and their content
+var inlineScriptRegex = regexp.MustCompile(`(?is)<script\b([^>]*)>(.*?)</script\s*>`)
+
+// matches a `src` attribute, i.e. a `<script>` that loads an external file
+var scriptSrcRegex = regexp.MustCompile(`(?i)\bsrc\s*=`)
+
+// reads a file (e.g. `/public/index.html`) of the source that is currently packaged
+func readSourceFile(path string) ([]byte, error) {
+	if sourceRoot == "" {
+		return nil, os.ErrNotExist
+	}
+
+	return os.ReadFile(filepath.Join(sourceRoot, filepath.FromSlash(strings.TrimPrefix(path, "/"))))
+}
+
+// check if the HTML contains at least one inline `<script>` (i.e., one that does not only load an external file)
+func HasInlineScript(html []byte) bool {
+	for _, match := range inlineScriptRegex.FindAllSubmatch(html, -1) {
+		if !scriptSrcRegex.Match(match[1]) && len(strings.TrimSpace(string(match[2]))) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// classifies a file in the `public` folder as a minified bundle, a vendored library, hand-written source or an asset
+func ClassifyPublicFile(path string) string {
+	return classifyPublicFile(path, func() ([]byte, error) { return readSourceFile(path) })
+}
+
+// the files of the `public` folder that may be hand-written source: all flavors of JavaScript and TypeScript (like
+// `.tsx`), and single-file components (like `.vue`)
+var publicScriptExtensions = append(scriptExtensions(""), singleFileComponentExtensions...)
+
+func classifyPublicFile(path string, readContent func() ([]byte, error)) string {
+	if minifiedRule.Matches(path) || HasExtensionFold(path, ".bundle.js", ".chunk.js") {
+		return PublicFileMinified
+	}
+
+	if HasExtensionFold(path, publicScriptExtensions...) {
+		// e.g. a jQuery with its license banner, or a webpack bundle called `main.js`. A `lib/` folder or a library-like
+		// file name alone does not make a file vendored (see `DetectVendoredLibrary`), and `-keep-vendored` keeps them
+		if content, err := readContent(); err == nil {
+			if vendoredDetectionEnabled && DetectVendoredLibrary(path, content) != nil {
+				return PublicFileVendored
+			}
+
+			if classification, _ := ClassifyScript(content); classification != ScriptSource {
+				return PublicFileMinified
+			}
+		}
+
+		return PublicFileHandWritten
+	}
+
+	// HTML files are only worth analyzing if they contain inline scripts
+	if HasExtensionFold(path, ".html", ".htm") {
+		if content, err := readContent(); err == nil && HasInlineScript(content) {
+			return PublicFileHandWritten
+		}
+	}
+
+	return PublicFileAsset
+}
+
+// decides whether a file in the `public` folder is kept (only hand-written source is), and logs the decision
+func evaluatePublicFile(path string, readContent func() ([]byte, error)) PathDecision {
+	// folders (which end with a `/`) are not classified. The files in them are checked one by one
+	if strings.HasSuffix(path, "/") {
+		return PathDecision{Rule: "public"}
+	}
+
+	classification := classifyPublicFile(path, readContent)
+	note := "`public` file classified as " + classification
+
+	if classification != PublicFileHandWritten {
+		log.Info("\tDropping `", path, "` from the `public` folder (", classification, ")")
+		return PathDecision{Rule: "public", Notes: []string{note}}
+	}
+
+	// some frameworks (like Next.js) only expect generated files in `public`. Thus, hand-written files are flagged there
+	if activeProfile.FlagPublicScripts {
+		log.Warn("\tKeeping the hand-written `", path, "` from the `public` folder. Please check that it is first-party code")
+	} else {
+		log.Info("\tKeeping `", path, "` from the `public` folder (", classification, ")")
+	}
+
+	return PathDecision{Notes: []string{note}}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Tests the classification of the files in `public` (only hand-written source is kept)
+func TestClassifyPublicFiles(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+
+	source := writeTestFiles(t, map[string]string{
+		"public/with-inline.html":    "<html><body><script>\n  $('#login').on('click', login);\n</script></body></html>",
+		"public/without-inline.html": "<html><body><script src=\"app.js\"></script><script>  </script></body></html>",
+		"public/js/jquery-3.6.0.js":  "/*! jQuery v3.6.0 | (c) OpenJS Foundation and other contributors | jquery.org/license */",
+		"public/js/vendor/chart.js":  "(function () { window.Chart = {} })()",
+		"public/lib/app.js":          "import { login } from './login.js'\n\nlogin()",
+		"public/chart.js":            "export function drawChart(data) {\n  return data.map((point) => point.value)\n}",
+		"public/widget.tsx":          "export const Widget = () => <div className=\"widget\">Hello</div>",
+		"public/App.vue":             "<template>\n  <div>{{ message }}</div>\n</template>\n\n<script>\nexport default { data: () => ({ message: 'Hello' }) }\n</script>",
+	})
+
+	sourceRoot = source
+	defer func() { sourceRoot = "" }()
+
+	testCases := []struct {
+		path     string
+		expected string
+	}{
+		{"/public/js/login.js", PublicFileHandWritten},
+		{"/public/sw.mjs", PublicFileHandWritten},
+		{"/public/js/app.min.js", PublicFileMinified},
+		{"/public/js/main.bundle.js", PublicFileMinified},
+		{"/public/js/jquery-3.6.0.js", PublicFileVendored},
+		{"/public/js/vendor/chart.js", PublicFileVendored},
+		// first-party code in a `lib` folder, or with the name of a library, is kept
+		{"/public/lib/app.js", PublicFileHandWritten},
+		{"/public/chart.js", PublicFileHandWritten},
+		{"/public/widget.tsx", PublicFileHandWritten},
+		{"/public/App.vue", PublicFileHandWritten},
+		{"/public/with-inline.html", PublicFileHandWritten},
+		{"/public/without-inline.html", PublicFileAsset},
+		{"/public/manifest.json", PublicFileAsset},
+	}
+
+	for _, testCase := range testCases {
+		if got := ClassifyPublicFile(testCase.path); got != testCase.expected {
+			t.Errorf("classified `%s` as `%s`, expected `%s`", testCase.path, got, testCase.expected)
+		}
+
+		if got := isRequired(testCase.path, nil); got != (testCase.expected == PublicFileHandWritten) {
+			t.Errorf("`isRequired()` returned %v for `%s`", got, testCase.path)
+		}
+	}
+
+	// folders are not classified (only the files in them)
+	if isRequired("/public/js/", nil) {
+		t.Error("the `/public/js/` folder should be omitted")
+	}
+
+	// `-keep-vendored` keeps the vendored libraries in `public` as well
+	vendoredDetectionEnabled = false
+	defer func() { vendoredDetectionEnabled = true }()
+
+	for _, path := range []string{"/public/js/jquery-3.6.0.js", "/public/js/vendor/chart.js"} {
+		if got := ClassifyPublicFile(path); got != PublicFileHandWritten {
+			t.Errorf("classified `%s` as `%s` with `-keep-vendored`", path, got)
+		}
+	}
+}
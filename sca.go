@@ -0,0 +1,103 @@
+package main
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// the prefix of the name of the SCA-only zip (e.g. `vc-output-sca_2023-Jan-04.zip`)
+const scaZipPrefix = "vc-output-sca_"
+
+// check if the file is required by Veracode SCA, i.e. a `package.json`, a lockfile (see `CheckIfSCAFileExists()`) or
+// the metadata of a package in `bower_components` (like `bower_components/jquery/.bower.json`)
+func IsSCAFile(path string) bool {
+	if CheckIfSCAFileExists(path) {
+		return true
+	}
+
+	if HasPathSegment(path, "bower_components") {
+		return HasBaseName(path, ".bower.json")
+	}
+
+	return HasBaseName(path, "package.json")
+}
+
+// creates a zip that only contains the files required by Veracode SCA (for the app itself and every workspace in it),
+// and returns the paths of these files
+func zipSCAFiles(source string, target string) ([]string, error) {
+	var scaFiles []string
+
+	archive, err := CreateAtomicArchive(target)
+	if err != nil {
+		return scaFiles, err
+	}
+
+	writer := archive.Writer
+
+	archive.Verify = func(path string) error {
+		manifest := &Manifest{Source: source}
+		for _, scaFile := range scaFiles {
+			manifest.Add(scaFile, PathDecision{})
+		}
+
+		return VerifyArchive(path, manifest)
+	}
+
+	// a `-target` directory inside the source may e.g. contain a `package.json` of the output of an earlier run
+	selfExclusion := NewSelfExclusion(archive.TempPath(), target)
+	selfExclusion.AddTargetDirectory(source, target)
+
+	err = filepath.Walk(source, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return &SourceError{err}
+		}
+
+		if selfExclusion.Excludes(path, info) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		// the manifests in `node_modules` belong to the dependencies (which SCA resolves via the lockfiles anyway)
+		if info.IsDir() && (info.Name() == "node_modules" || info.Name() == ".git") {
+			return filepath.SkipDir
+		}
+
+		relPath, err := filepath.Rel(source, path)
+		if err != nil {
+			return err
+		}
+
+		name := filepath.ToSlash(relPath)
+		if info.IsDir() || !IsSCAFile("/"+name) {
+			return nil
+		}
+
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+
+		header.Name = name
+		header.Method = zip.Deflate
+
+		headerWriter, err := writer.CreateHeader(header)
+		if err != nil {
+			return &WriteError{err}
+		}
+
+		if err := copySourceFile(headerWriter, path); err != nil {
+			return err
+		}
+
+		log.Info("\tAdding `", name, "` to the SCA archive")
+		scaFiles = append(scaFiles, name)
+		return nil
+	})
+
+	return scaFiles, archive.Commit(err)
+}
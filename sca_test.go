@@ -0,0 +1,69 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Integration test for `zipSCAFiles()` with `./sample-projects/sample-node-project`
+func TestZipSCAFilesWithNodeSample(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+
+	targetPath := filepath.Join(t.TempDir(), "test-output-sca.zip")
+	if _, err := zipSCAFiles("./sample-projects/sample-node-project", targetPath); err != nil {
+		t.Fatal(err)
+	}
+
+	var zipFileContents []string
+	for _, zipFile := range readZip(targetPath).File {
+		zipFileContents = append(zipFileContents, zipFile.Name)
+	}
+
+	expectedFilesInOutputZip := []string{"package.json", "package-lock.json", "bower_components/bower.json"}
+	sort.Strings(expectedFilesInOutputZip)
+	sort.Strings(zipFileContents)
+
+	if !reflect.DeepEqual(zipFileContents, expectedFilesInOutputZip) {
+		t.Errorf("Got: %v", zipFileContents)
+		t.Errorf("Expected: %v", expectedFilesInOutputZip)
+	}
+}
+
+// Tests that the manifests and lockfiles of every workspace end up in the SCA zip
+func TestZipSCAFilesWithWorkspaces(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+
+	source := writeTestFiles(t, map[string]string{
+		"package.json":                   "{}",
+		"yarn.lock":                      "{}",
+		"src/app.js":                     "{}",
+		"packages/api/package.json":      "{}",
+		"packages/api/package-lock.json": "{}",
+		"packages/api/index.js":          "{}",
+		"packages/web/package.json":      "{}",
+		"packages/web/bower_components/jquery/.bower.json":    "{}",
+		"packages/web/bower_components/jquery/dist/jquery.js": "{}",
+		"node_modules/express/package.json":                   "{}",
+	})
+
+	scaFiles, err := zipSCAFiles(source, filepath.Join(t.TempDir(), "test-output-sca.zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedFiles := []string{
+		"package.json", "yarn.lock", "packages/api/package.json", "packages/api/package-lock.json",
+		"packages/web/package.json", "packages/web/bower_components/jquery/.bower.json",
+	}
+	sort.Strings(expectedFiles)
+	sort.Strings(scaFiles)
+
+	if !reflect.DeepEqual(scaFiles, expectedFiles) {
+		t.Errorf("Got: %v", scaFiles)
+		t.Errorf("Expected: %v", expectedFiles)
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// the files the packager writes (like the output zip) or runs from (its executable), which must never be packaged
+// themselves, e.g. when running `veracode-js-packager -source . -target .`. They are identified by their resolved
+// absolute path, and by their identity on disk (i.e. their inode), so that a hard link or another path to the same file
+// is excluded as well. The files an earlier run wrote next to its zip (like `vc-output_2023-Jan-04.manifest.json`) are
+// excluded by their name. Other archives (like the zips of earlier runs) are left to the `archives` rule
+type SelfExclusion struct {
+	paths map[string]bool
+	files []os.FileInfo
+	// the `-target` directory, if it lies inside the `-source` (its whole subtree is skipped)
+	directories map[string]bool
+	// the directory of the output zip, in which the files of earlier runs are excluded as well
+	outputDirectories map[string]bool
+	// the `-source` (as walked) and its resolved path, so that the walked paths do not have to be resolved one by one
+	source         string
+	resolvedSource string
+}
+
+// returns the resolved absolute path (following symlinks if the path exists)
+func resolvePath(path string) string {
+	if absolutePath, err := filepath.Abs(path); err == nil {
+		path = absolutePath
+	}
+
+	if resolvedPath, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolvedPath
+	}
+
+	return filepath.Clean(path)
+}
+
+// creates the self-exclusion for the provided output files, and for the running executable
+func NewSelfExclusion(outputPaths ...string) *SelfExclusion {
+	exclusion := &SelfExclusion{paths: map[string]bool{}, directories: map[string]bool{}, outputDirectories: map[string]bool{}}
+
+	if executable, err := os.Executable(); err == nil {
+		outputPaths = append(outputPaths, executable)
+	}
+
+	for _, path := range outputPaths {
+		exclusion.Add(path)
+	}
+
+	return exclusion
+}
+
+// excludes another file (which does not have to exist yet)
+func (exclusion *SelfExclusion) Add(path string) {
+	exclusion.paths[resolvePath(path)] = true
+
+	if info, err := os.Stat(path); err == nil && !info.IsDir() {
+		exclusion.files = append(exclusion.files, info)
+	}
+}
+
+// excludes the directory of the output zip (and everything in it) if it lies inside the source, like `out` for
+// `-source . -target ./out`. If both are the same directory, only the output files themselves (and the ones of earlier
+// runs) are excluded
+func (exclusion *SelfExclusion) AddTargetDirectory(source string, target string) {
+	exclusion.source = source
+	exclusion.resolvedSource = resolvePath(source)
+
+	targetDir := resolvePath(filepath.Dir(target))
+	exclusion.outputDirectories[targetDir] = true
+	if relPath, inSource := GetTargetInSource(source, targetDir); inSource && relPath != "." {
+		exclusion.directories[targetDir] = true
+	}
+}
+
+// returns the resolved absolute path of a path of the walk over the `-source`. The walk does not follow symlinks, so
+// only its root has to be resolved
+func (exclusion *SelfExclusion) resolve(path string) string {
+	if exclusion.resolvedSource != "" {
+		if relPath, err := filepath.Rel(exclusion.source, path); err == nil && relPath != ".." &&
+			!strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+			return filepath.Join(exclusion.resolvedSource, relPath)
+		}
+	}
+
+	return resolvePath(path)
+}
+
+// check if the file or folder (as found by the walk over the source) is one of the excluded ones. The walk is expected
+// to skip an excluded folder (i.e. return `filepath.SkipDir`)
+func (exclusion *SelfExclusion) Excludes(path string, info os.FileInfo) bool {
+	if info != nil && info.IsDir() {
+		return len(exclusion.directories) > 0 && exclusion.directories[exclusion.resolve(path)]
+	}
+
+	// the identity of the file is known without any further system call
+	if info != nil {
+		for _, file := range exclusion.files {
+			if os.SameFile(file, info) {
+				return true
+			}
+		}
+	}
+
+	resolvedPath := exclusion.resolve(path)
+	if exclusion.paths[resolvedPath] {
+		return true
+	}
+
+	return exclusion.outputDirectories[filepath.Dir(resolvedPath)] && IsOutputFileName(filepath.Base(resolvedPath))
+}
+
+// check if the file name is the one of a file the packager writes next to its output zip, like
+// `vc-output_2023-Jan-04.manifest.json`, `vc-output_2023-Jan-04.upload.json` or
+// `vc-output-pipeline_2023-Jan-04.pipeline.json`
+func IsOutputFileName(name string) bool {
+	for _, prefix := range []string{outputZipPrefix, pipelineZipPrefix, scaZipPrefix} {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+
+		for _, suffix := range []string{manifestSuffix, descriptorSuffix, pipelineMetadataSuffix} {
+			if strings.HasSuffix(name, suffix) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// returns the path of the `-target` directory relative to the `-source` (e.g. `out`) if it lies inside of it, i.e. if
+// the output is written into the tree that is packaged. The path is `.` if both are the same directory
+func GetTargetInSource(source string, targetDir string) (string, bool) {
+	relPath, err := filepath.Rel(resolvePath(source), resolvePath(targetDir))
+	if err != nil || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+
+	return filepath.ToSlash(relPath), true
+}
+
+// logs where the output is written to if the `-target` directory lies inside the `-source` (as with the Docker image,
+// which runs `-source . -target .`), and warns if the rules would have packaged the `-target` directory itself
+func CheckTargetInSource(source string, targetDir string, testsPaths []string) {
+	relPath, inSource := GetTargetInSource(source, targetDir)
+	if !inSource {
+		return
+	}
+
+	if relPath == "." {
+		log.Info("\tThe `-target` is the `-source` directory: the output zip (and the files next to it) are not packaged\n\n")
+		return
+	}
+
+	if decision := evaluatePath("/"+relPath+"/", testsPaths); decision.Rule != "" {
+		log.Info("\tThe `-target` directory `", relPath, "/` lies inside the `-source`, and is omitted (`", decision.Rule, "` rule)\n\n")
+		return
+	}
+
+	log.Warn("\tThe `-target` directory `", relPath, "/` lies inside the `-source`, and would have been packaged itself "+
+		"(including the output of earlier runs)...")
+	log.Warn("\tIt is omitted, but please consider a `-target` outside of the `-source`\n\n")
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func TestSelfExclusion(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(dir, "vc-output.zip")
+	other := filepath.Join(dir, "other.zip")
+	for _, path := range []string{output, other} {
+		if err := os.WriteFile(path, []byte("zip"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	exclusion := NewSelfExclusion(output)
+
+	// another path to the same file (via a hard link) is excluded as well
+	link := filepath.Join(dir, "link.zip")
+	if err := os.Link(output, link); err != nil {
+		t.Skip("hard links are not supported: ", err)
+	}
+
+	pathsToExcluded := map[string]bool{
+		output:                                   true,
+		filepath.Join(dir, ".", "vc-output.zip"): true,
+		link:                                     true,
+		other:                                    false,
+		dir:                                      false,
+	}
+
+	for path, expected := range pathsToExcluded {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := exclusion.Excludes(path, info); got != expected {
+			t.Errorf("%s: Got: %v, Expected: %v", path, got, expected)
+		}
+	}
+
+	// the running executable is always excluded
+	executable, err := os.Executable()
+	if err != nil {
+		t.Skip("the executable is unknown: ", err)
+	}
+
+	info, err := os.Stat(executable)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !exclusion.Excludes(executable, info) {
+		t.Errorf("The executable `%s` was not excluded", executable)
+	}
+}
+
+// with `-target` inside the `-source`, the output is not packaged - but files merely named like the packager are, and
+// archives of earlier runs are omitted via the `archives` rule
+func TestZipSourceExcludesItsOutput(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+
+	source := writeTestFiles(t, map[string]string{
+		"app.js":                         "console.log('app')",
+		"src/vc-js-packager-client.js":   "export const client = {}",
+		"veracode-js-packager.config.js": "module.exports = {}",
+		"vc-output_2023-Jan-04.zip":      "an earlier run",
+	})
+
+	target := filepath.Join(source, "vc-output.zip")
+	manifest, err := zipSource(source, target, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	zipReader := readZip(target)
+	var zipFiles []string
+	for _, file := range zipReader.File {
+		if !strings.HasSuffix(file.Name, "/") {
+			zipFiles = append(zipFiles, file.Name)
+		}
+	}
+	sort.Strings(zipFiles)
+
+	expected := []string{"app.js", "src/vc-js-packager-client.js", "veracode-js-packager.config.js"}
+	if !reflect.DeepEqual(zipFiles, expected) {
+		t.Errorf("Got: %v, Expected: %v", zipFiles, expected)
+	}
+
+	rules := map[string]string{}
+	for _, entry := range manifest.Entries {
+		rules[entry.Path] = entry.Rule
+	}
+
+	if rules["vc-output_2023-Jan-04.zip"] != "archives" {
+		t.Errorf("Got: %v", rules)
+	}
+
+	if _, found := rules["vc-output.zip"]; found {
+		t.Errorf("The output zip was walked: %v", rules)
+	}
+}
+
+func TestGetTargetInSource(t *testing.T) {
+	source := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(source, "out", "zips"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	targetsToRelPaths := map[string]string{
+		source:                                  ".",
+		filepath.Join(source, "out"):            "out",
+		filepath.Join(source, "out", "zips"):    "out/zips",
+		filepath.Join(source, "out", "..", "."): ".",
+	}
+
+	for target, expected := range targetsToRelPaths {
+		if got, inSource := GetTargetInSource(source, target); !inSource || got != expected {
+			t.Errorf("%s: Got: %s (%v), Expected: %s", target, got, inSource, expected)
+		}
+	}
+
+	for _, target := range []string{t.TempDir(), filepath.Dir(source), source + "-sibling"} {
+		if got, inSource := GetTargetInSource(source, target); inSource {
+			t.Errorf("%s: Got: %s", target, got)
+		}
+	}
+}
+
+// with `-target ./out`, the whole `out` folder (e.g. with the output of earlier runs) is skipped by the walks, and
+// recorded in the manifest as a single entry
+func TestZipSourceSkipsTargetDirectory(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+
+	source := writeTestFiles(t, map[string]string{
+		"app.js":                        "console.log('app')",
+		"package.json":                  "{}",
+		"out/vc-output_2023-Jan-04.zip": "an earlier run",
+		"out/vc-output_2023-Jan-04.manifest.json": "{}",
+		"out/earlier-run.js":                      "console.log('earlier')",
+		"out/package.json":                        "{}",
+		"output/kept-by-name.js":                  "console.log('kept')",
+		"src/out/kept-too.js":                     "console.log('kept')",
+	})
+
+	target := filepath.Join(source, "out", "vc-output.zip")
+	manifest, err := zipSource(source, target, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var paths []string
+	rules := map[string]string{}
+	for _, entry := range manifest.Entries {
+		paths = append(paths, entry.Path)
+		rules[entry.Path] = entry.Rule
+	}
+	sort.Strings(paths)
+
+	expected := []string{"app.js", "out/", "output/kept-by-name.js", "package.json", "src/out/kept-too.js"}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("Got: %v, Expected: %v", paths, expected)
+	}
+
+	if rules["out/"] != "target-directory" {
+		t.Errorf("Got: %v", rules)
+	}
+
+	scaFiles, err := zipSCAFiles(source, filepath.Join(source, "out", "vc-output-sca.zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(scaFiles, []string{"package.json"}) {
+		t.Errorf("Got: %v", scaFiles)
+	}
+}
+
+func TestIsOutputFileName(t *testing.T) {
+	namesToExpected := map[string]bool{
+		"vc-output_2023-Jan-04.manifest.json":             true,
+		"vc-output_2023-Jan-04.upload.json":               true,
+		"vc-output-pipeline_2023-Jan-04.pipeline.json":    true,
+		"vc-output-pipeline_2023-Jan-04.manifest.json":    true,
+		"vc-output-sca_2023-Jan-04.manifest.json":         true,
+		"vc-output_2023-Jan-04.zip":                       false,
+		"vc-output.json":                                  false,
+		"my-vc-output_2023-Jan-04.manifest.json":          false,
+		"vc-output_2023-Jan-04.manifest.json.backup.json": false,
+	}
+
+	for name, expected := range namesToExpected {
+		if got := IsOutputFileName(name); got != expected {
+			t.Errorf("%s: Got: %v, Expected: %v", name, got, expected)
+		}
+	}
+}
+
+// with `-source . -target .`, the manifest, descriptor and pipeline metadata of an earlier run are not packaged by the
+// next one - but files of the app that are merely named like them (outside of the `-target`) are
+func TestRunTwiceIntoTheSource(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+	defer log.SetLevel(log.FatalLevel)
+
+	defer func(url string) { latestRelease = url }(latestRelease)
+	latestRelease = ""
+
+	source := writeTestFiles(t, map[string]string{
+		"app.js":                            "console.log('app')",
+		"package.json":                      `{"name": "app"}`,
+		"vc-output_2023-Jan-04.upload.json": "{}",
+		"src/vc-output_2023-Jan-04.manifest.json": "{}",
+	})
+
+	for _, args := range [][]string{
+		{"-quiet", "-source", source, "-target", source, "-mode", "pipeline", "-manifest"},
+		{"-quiet", "-source", source, "-target", source, "-manifest", "-descriptor"},
+		{"-quiet", "-source", source, "-target", source, "-manifest", "-descriptor"},
+	} {
+		if got := run(args); got != ExitOK {
+			t.Fatalf("%v: Got: %d, Expected: %d", args, got, ExitOK)
+		}
+	}
+
+	archives, err := filepath.Glob(filepath.Join(source, outputZipPrefix+"*.zip"))
+	if err != nil || len(archives) != 1 {
+		t.Fatalf("Got: %v (%v)", archives, err)
+	}
+
+	zipReader := readZip(archives[0])
+	var zipFiles []string
+	for _, file := range zipReader.File {
+		if !strings.HasSuffix(file.Name, "/") {
+			zipFiles = append(zipFiles, file.Name)
+		}
+	}
+	sort.Strings(zipFiles)
+
+	expected := []string{"app.js", "package.json", "src/vc-output_2023-Jan-04.manifest.json"}
+	if !reflect.DeepEqual(zipFiles, expected) {
+		t.Errorf("Got: %v, Expected: %v", zipFiles, expected)
+	}
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// if `true`, the `<script>` blocks of single-file components are additionally added to the zip as `.js`/`.ts` files
+// next to the components (set via `-extract-sfc-scripts`)
+var extractSFCScriptsEnabled bool = false
+
+// the extensions of single-file components (Vue, Svelte and Astro), whose scripts live next to templates and styles
+var singleFileComponentExtensions = []string{".vue", ".svelte", ".astro"}
+
+// matches the `lang` of a `<script>` block, like `lang="ts"`
+var scriptLangRegex = regexp.MustCompile(`(?i)\blang\s*=\s*["']?(ts|typescript|tsx|jsx|js|javascript)\b`)
+
+// matches the `type` of a `<script>` block that does not contain JavaScript, like `type="application/ld+json"` or
+// `type="text/x-template"`
+var nonScriptTypeRegex = regexp.MustCompile(`(?i)\btype\s*=\s*["']?(application/(ld\+)?json|text/(x-|html|template))`)
+
+// matches the `setup` attribute of a Vue `<script setup>` block
+var scriptSetupRegex = regexp.MustCompile(`(?i)\bsetup\b`)
+
+// matches the frontmatter of an Astro component (which is TypeScript)
+var astroFrontmatterRegex = regexp.MustCompile(`(?s)\A\s*---\r?\n(.*?)\r?\n---`)
+
+// a script that was extracted from a single-file component (or a template)
+type ExtractedScript struct {
+	// the path in the output zip, e.g. `src/App.vue.script.js`
+	Name    string
+	Content []byte
+	// the file the script was extracted from, and the line the script starts at
+	Origin string
+	Line   int
+}
+
+// check if the file is a Vue, Svelte or Astro single-file component
+func IsSingleFileComponent(path string) bool {
+	return HasExtensionFold(path, singleFileComponentExtensions...)
+}
+
+// extracts the `<script>` blocks (and the frontmatter of Astro components) of a single-file component. The extracted
+// scripts are padded with empty lines, so that their line numbers match the ones in the component
+func ExtractSFCScripts(name string, content []byte) []ExtractedScript {
+	var scripts []ExtractedScript
+
+	add := func(kind string, extension string, start int, end int) {
+		script := content[start:end]
+		if len(bytes.TrimSpace(script)) == 0 {
+			return
+		}
+
+		line := bytes.Count(content[:start], []byte("\n")) + 1
+		scriptName := fmt.Sprintf("%s.%s.%s", name, kind, extension)
+		for i := 2; containsScriptName(scripts, scriptName); i++ {
+			scriptName = fmt.Sprintf("%s.%s-%d.%s", name, kind, i, extension)
+		}
+
+		scripts = append(scripts, ExtractedScript{
+			Name:    scriptName,
+			Content: padScript(script, line, name),
+			Origin:  name,
+			Line:    line,
+		})
+	}
+
+	if HasExtensionFold(name, ".astro") {
+		if match := astroFrontmatterRegex.FindSubmatchIndex(content); match != nil {
+			add("frontmatter", "ts", match[2], match[3])
+		}
+	}
+
+	for _, match := range inlineScriptRegex.FindAllSubmatchIndex(content, -1) {
+		attributes := content[match[2]:match[3]]
+		if scriptSrcRegex.Match(attributes) || nonScriptTypeRegex.Match(attributes) {
+			continue
+		}
+
+		extension := "js"
+		if lang := scriptLangRegex.FindSubmatch(attributes); lang != nil {
+			switch strings.ToLower(string(lang[1])) {
+			case "ts", "typescript":
+				extension = "ts"
+			case "tsx", "jsx":
+				extension = strings.ToLower(string(lang[1]))
+			}
+		}
+
+		kind := "script"
+		if scriptSetupRegex.Match(attributes) {
+			kind = "script-setup"
+		}
+
+		add(kind, extension, match[4], match[5])
+	}
+
+	return scripts
+}
+
+func containsScriptName(scripts []ExtractedScript, name string) bool {
+	for _, script := range scripts {
+		if script.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// prepends a comment with the origin of the script and empty lines, so that the script starts at the same line as in
+// the file it was extracted from
+func padScript(script []byte, line int, origin string) []byte {
+	var padded bytes.Buffer
+
+	if line > 1 {
+		padded.WriteString("// extracted from `" + origin + "` by the Veracode JavaScript Packager (line numbers match the original)")
+		padded.WriteString(strings.Repeat("\n", line-1))
+	}
+
+	// the script usually starts with the rest of the line of its `<script>` tag
+	padded.Write(script)
+	return padded.Bytes()
+}
+
+// writes a file that does not exist in the source (like a recovered or extracted script) to the zip
+func writeGeneratedFile(writer *zip.Writer, name string, content []byte) error {
+	headerWriter, err := writer.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: time.Now(),
+	})
+	if err != nil {
+		return &WriteError{err}
+	}
+
+	if _, err := headerWriter.Write(content); err != nil {
+		return &WriteError{err}
+	}
+
+	return nil
+}
+
+// adds the `<script>` blocks of the single-file components that are part of the zip as `.js`/`.ts` files next to them
+func addExtractedSFCScripts(writer *zip.Writer, manifest *Manifest) error {
+	existing := map[string]bool{}
+	var components []string
+
+	for _, entry := range manifest.Entries {
+		existing[entry.Path] = true
+		if entry.Included && IsSingleFileComponent(entry.Path) {
+			components = append(components, entry.Path)
+		}
+	}
+
+	for _, component := range components {
+		content, err := readSourceFile("/" + component)
+		if err != nil {
+			return &SourceError{err}
+		}
+
+		for _, script := range ExtractSFCScripts(component, content) {
+			// never overwrite a file of the source
+			if existing[script.Name] {
+				continue
+			}
+
+			existing[script.Name] = true
+			manifest.Add(script.Name, PathDecision{Notes: []string{fmt.Sprintf("extracted from `%s` (line %d)", script.Origin, script.Line)}, Generated: true})
+
+			if err := writeGeneratedFile(writer, script.Name, script.Content); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// confirms that the single-file components of the app are part of the zip, and adds a smell for the ones that were
+// omitted by a rule other than the test rules (as they likely contain first-party code)
+func CheckSingleFileComponents(manifest *Manifest) {
+	kept := 0
+	var omitted []string
+
+	for _, entry := range manifest.Entries {
+		if !IsSingleFileComponent(entry.Path) {
+			continue
+		}
+
+		if entry.Included {
+			kept++
+		} else if entry.Rule != "test-folders" && entry.Rule != testFileRule.Name && entry.Rule != "test-runners" {
+			omitted = append(omitted, fmt.Sprintf("%s (rule: %s)", entry.Path, entry.Rule))
+		}
+	}
+
+	if kept > 0 {
+		log.Info("\tKept ", kept, " single-file component(s) (`.vue`, `.svelte` or `.astro`)")
+	}
+
+	if len(omitted) > 0 {
+		log.Warn("\tOmitted ", len(omitted), " single-file component(s) that likely contain first-party code: ", strings.Join(omitted, ", "))
+		manifest.Smells = append(manifest.Smells, fmt.Sprintf("%d single-file component(s) were omitted: %s",
+			len(omitted), strings.Join(omitted, ", ")))
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Tests the extraction of the scripts of Vue, Svelte and Astro components
+func TestExtractSFCScripts(t *testing.T) {
+	testCases := []struct {
+		name     string
+		content  string
+		expected map[string]int
+	}{
+		{
+			"src/App.vue",
+			"<template>\n  <div/>\n</template>\n\n<script lang=\"ts\">\nexport default {}\n</script>\n" +
+				"<script setup>\nconst a = 1\n</script>\n<style>div {}</style>\n",
+			map[string]int{"src/App.vue.script.ts": 5, "src/App.vue.script-setup.js": 8},
+		},
+		{
+			"src/Counter.svelte",
+			"<script context=\"module\">\nexport const x = 1;\n</script>\n<script>\nlet count = 0;\n</script>\n" +
+				"<script type=\"application/ld+json\">{}</script>\n<script src=\"/external.js\"></script>\n<button>{count}</button>\n",
+			map[string]int{"src/Counter.svelte.script.js": 1, "src/Counter.svelte.script-2.js": 4},
+		},
+		{
+			"src/pages/index.astro",
+			"---\nconst title = 'Home';\n---\n<h1>{title}</h1>\n<script>\ndocument.title = 'x';\n</script>\n",
+			map[string]int{"src/pages/index.astro.frontmatter.ts": 2, "src/pages/index.astro.script.js": 5},
+		},
+		// extensions are compared case-insensitively, like for all other rules
+		{
+			"src/pages/About.ASTRO",
+			"---\nconst title = 'About';\n---\n<h1>{title}</h1>\n",
+			map[string]int{"src/pages/About.ASTRO.frontmatter.ts": 2},
+		},
+	}
+
+	for _, path := range []string{"/src/App.VUE", "/src/Counter.Svelte", "/src/pages/About.ASTRO"} {
+		if !IsSingleFileComponent(path) {
+			t.Errorf("`%s` should be a single-file component", path)
+		}
+	}
+
+	for _, testCase := range testCases {
+		scripts := ExtractSFCScripts(testCase.name, []byte(testCase.content))
+		if len(scripts) != len(testCase.expected) {
+			t.Errorf("expected %d scripts for `%s`, got %+v", len(testCase.expected), testCase.name, scripts)
+			continue
+		}
+
+		originalLines := strings.Split(testCase.content, "\n")
+		for _, script := range scripts {
+			line, ok := testCase.expected[script.Name]
+			if !ok || script.Line != line {
+				t.Errorf("unexpected script `%s` (line %d) for `%s`", script.Name, script.Line, testCase.name)
+				continue
+			}
+
+			// the extracted script has to keep the line numbers of the component
+			scriptLines := strings.Split(string(script.Content), "\n")
+			for i := line; i < len(scriptLines); i++ {
+				if strings.TrimSpace(scriptLines[i]) != "" && scriptLines[i] != originalLines[i] {
+					t.Errorf("line %d of `%s` is `%s`, expected `%s`", i+1, script.Name, scriptLines[i], originalLines[i])
+				}
+			}
+		}
+	}
+}
+
+// Integration test for `zipSource()` with `-extract-sfc-scripts` and `./sample-projects/sample-vue-project`
+func TestZipSourceWithVueSampleAndExtractedScripts(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+
+	extractSFCScriptsEnabled = true
+	defer func() { extractSFCScriptsEnabled = false }()
+
+	manifest, err := zipSource("./sample-projects/sample-vue-project", filepath.Join(t.TempDir(), "test-output.zip"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	included := map[string]bool{}
+	for _, entry := range manifest.Entries {
+		included[entry.Path] = entry.Included
+	}
+
+	for _, path := range []string{"src/App.vue", "src/App.vue.script.js", "src/components/VTag.vue.script.js"} {
+		if !included[path] {
+			t.Errorf("expected `%s` to be part of the zip", path)
+		}
+	}
+
+	for _, smell := range manifest.Smells {
+		if strings.Contains(smell, "single-file component") {
+			t.Errorf("unexpected smell: %s", smell)
+		}
+	}
+}
+
+// Tests that the stylesheet rule covers the common preprocessors
+func TestStyleSheetRule(t *testing.T) {
+	for _, path := range []string{"/a.css", "/a.scss", "/a.sass", "/a.less", "/a.styl", "/a.pcss"} {
+		if !IsStyleSheet(path) {
+			t.Errorf("`%s` should be a style sheet", path)
+		}
+	}
+
+	for _, path := range []string{"/src/App.vue", "/src/less.js", "/src/stylesheet.ts"} {
+		if IsStyleSheet(path) {
+			t.Errorf("`%s` should not be a style sheet", path)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// if `true`, the original sources are recovered from the source maps of the app (set via `-recover-sources`)
+var recoverSourcesEnabled bool = false
+
+// the folder in the output zip that contains the recovered sources (named so that it does not collide with a folder of
+// the app, like a `recovered/` route)
+const recoveredFolder = "recovered"
+
+// the parts of a (version 3) source map that we need
+type sourceMap struct {
+	File           string    `json:"file"`
+	SourceRoot     string    `json:"sourceRoot"`
+	Sources        []string  `json:"sources"`
+	SourcesContent []*string `json:"sourcesContent"`
+}
+
+// a source that was recovered from a source map
+type RecoveredSource struct {
+	// the path in the output zip, e.g. `recovered/src/app.js`
+	Name    string
+	Content []byte
+	// the source map it was recovered from, e.g. `/dist/main.js.map`
+	SourceMap string
+}
+
+// the result of recovering the sources from all source maps of an app
+type RecoveredSources struct {
+	Sources []RecoveredSource
+	// the bundles (e.g. `/dist/main.js`) whose original sources were recovered, and which are thus dropped
+	Bundles map[string]bool
+}
+
+// matches the scheme of a source, like `webpack://` in `webpack:///./src/app.js`
+var sourceSchemeRegex = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]*://`)
+
+// recovers the original first-party sources from the `sourcesContent` of all source maps in `source`
+func RecoverSources(source string) (*RecoveredSources, error) {
+	recovered := &RecoveredSources{Bundles: map[string]bool{}}
+	seen := map[string]bool{}
+
+	err := filepath.Walk(source, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// source maps of 3rd party code are of no interest
+		if info.IsDir() && info.Name() == "node_modules" {
+			return filepath.SkipDir
+		}
+
+		if info.IsDir() || !HasExtension(filePath, ".map") {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(source, filePath)
+		if err != nil {
+			return err
+		}
+
+		mapPath := "/" + filepath.ToSlash(relPath)
+
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+
+		var parsedMap sourceMap
+		if err := json.Unmarshal(content, &parsedMap); err != nil {
+			log.Warn("\tCould not parse the source map `", mapPath, "`: ", err)
+			return nil
+		}
+
+		recoveredFromMap := 0
+		for i, sourceName := range parsedMap.Sources {
+			if i >= len(parsedMap.SourcesContent) || parsedMap.SourcesContent[i] == nil {
+				continue
+			}
+
+			name, ok := GetRecoveredSourceName(mapPath, parsedMap.SourceRoot, sourceName)
+			if !ok || seen[name] {
+				continue
+			}
+
+			seen[name] = true
+			recoveredFromMap++
+			recovered.Sources = append(recovered.Sources, RecoveredSource{
+				Name:      name,
+				Content:   []byte(*parsedMap.SourcesContent[i]),
+				SourceMap: mapPath,
+			})
+		}
+
+		// the bundle is dropped if (and only if) its original sources were recovered
+		if recoveredFromMap > 0 {
+			bundle := strings.TrimSuffix(mapPath, ".map")
+			if parsedMap.File != "" {
+				bundle = path.Join(path.Dir(mapPath), NormalizePath(parsedMap.File))
+			}
+
+			recovered.Bundles[bundle] = true
+			log.Info("\tRecovered ", recoveredFromMap, " original source(s) from `", mapPath, "`")
+		}
+
+		return nil
+	})
+
+	sort.Slice(recovered.Sources, func(i, j int) bool {
+		return recovered.Sources[i].Name < recovered.Sources[j].Name
+	})
+
+	return recovered, err
+}
+
+// returns the path in the output zip (e.g. `recovered/src/app.js`) for a source of a source map, or `false` if the
+// source is not first-party code (like the webpack runtime or a file in `node_modules`)
+func GetRecoveredSourceName(mapPath string, sourceRoot string, sourceName string) (string, bool) {
+	name := NormalizePath(sourceName)
+	if sourceRoot != "" && !sourceSchemeRegex.MatchString(name) {
+		name = strings.TrimSuffix(NormalizePath(sourceRoot), "/") + "/" + name
+	}
+
+	if scheme := sourceSchemeRegex.FindString(name); scheme != "" {
+		name = strings.TrimPrefix(name, scheme)
+
+		// webpack 5 adds the name of the app, e.g. `webpack://my-app/./src/app.js`
+		if !strings.HasPrefix(name, "/") {
+			if index := strings.Index(name, "/"); index >= 0 {
+				name = name[index:]
+			}
+		}
+
+		name = path.Clean("/" + name)
+	} else if !strings.HasPrefix(name, "/") {
+		// relative sources are relative to the source map, e.g. `../src/app.ts` for `/dist/main.js.map`
+		name = path.Join(path.Dir(mapPath), name)
+	}
+
+	// sources outside of the source (like `../../src/app.ts` for `/main.js.map`) are put in the recovered folder anyway
+	var segments []string
+	for _, segment := range PathSegments(path.Clean("/" + name)) {
+		if segment != ".." {
+			segments = append(segments, segment)
+		}
+	}
+
+	// skip the webpack runtime (e.g. `webpack/bootstrap`), externals and 3rd party code
+	if len(segments) == 0 || segments[0] == "webpack" || segments[0] == "(webpack)" ||
+		strings.HasPrefix(segments[0], "external ") || strings.HasPrefix(segments[0], "ignored|") ||
+		HasPathSegment(name, "node_modules") {
+		return "", false
+	}
+
+	return recoveredFolder + "/" + strings.Join(segments, "/"), true
+}
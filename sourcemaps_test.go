@@ -0,0 +1,90 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Tests how the sources of a source map are mapped to paths in the output zip
+func TestGetRecoveredSourceName(t *testing.T) {
+	testCases := []struct {
+		mapPath    string
+		sourceRoot string
+		source     string
+		expected   string
+	}{
+		{"/dist/main.js.map", "", "webpack:///./src/app.js", "recovered/src/app.js"},
+		{"/dist/main.js.map", "", "webpack://my-app/./src/app.js", "recovered/src/app.js"},
+		{"/dist/main.js.map", "", "../src/app.ts", "recovered/src/app.ts"},
+		{"/main.js.map", "", "../../src/app.ts", "recovered/src/app.ts"},
+		{"/dist/main.js.map", "src", "app.ts", "recovered/dist/src/app.ts"},
+		{"/dist/main.js.map", "", "webpack:///webpack/bootstrap", ""},
+		{"/dist/main.js.map", "", "webpack://my-app/webpack/runtime/define property getters", ""},
+		{"/dist/main.js.map", "", "webpack:///./node_modules/lodash/lodash.js", ""},
+		{"/dist/main.js.map", "", "webpack:///external \"react\"", ""},
+	}
+
+	for _, testCase := range testCases {
+		name, ok := GetRecoveredSourceName(testCase.mapPath, testCase.sourceRoot, testCase.source)
+		if (testCase.expected == "" && ok) || name != testCase.expected {
+			t.Errorf("got `%s` (%v) for `%s`, expected `%s`", name, ok, testCase.source, testCase.expected)
+		}
+	}
+}
+
+// Integration test for `zipSource()` with `-recover-sources`
+func TestZipSourceWithRecoveredSources(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+
+	source := writeTestFiles(t, map[string]string{
+		"package.json":          `{"name": "bundled-app"}`,
+		"static/js/main.js":     "(() => { function __webpack_require__(id) {} })();\n//# sourceMappingURL=main.js.map\n",
+		"static/js/other.js":    "console.log('not bundled');\n",
+		"static/js/main.js.map": `{"version": 3, "file": "main.js", "sources": ["webpack:///webpack/bootstrap", "webpack:///./src/app.js", "webpack:///./node_modules/lodash/lodash.js", "webpack:///./src/styles.scss", "webpack:///./src/prebuilt.js", "webpack:///./src/no-content.js"], "sourcesContent": ["// runtime", "console.log('app');", "// lodash", "body {}", "function __webpack_require__(id) {}"]}`,
+		// a file of the app in `recovered/` is kept (as long as it does not collide with a recovered source)
+		"recovered/routes.js": "export const routes = [];\n",
+	})
+
+	recoverSourcesEnabled = true
+	defer func() { recoverSourcesEnabled = false }()
+
+	// the recovered `prebuilt.js` is classified by its recovered content (as a bundle)
+	zipFileContents := generateZipAndReturnItsFiles(source, filepath.Join(t.TempDir(), "test-output.zip"), nil)
+	expectedFilesInOutputZip := []string{"package.json", "static/js/other.js", "recovered/routes.js", "recovered/src/app.js"}
+
+	sort.Strings(expectedFilesInOutputZip)
+	sort.Strings(zipFileContents)
+
+	if !reflect.DeepEqual(zipFileContents, expectedFilesInOutputZip) {
+		t.Errorf("Got: %v", zipFileContents)
+		t.Errorf("Expected: %v", expectedFilesInOutputZip)
+	}
+}
+
+// a file of the source with the path of a recovered source fails the run (instead of ending up twice in the zip)
+func TestZipSourceWithCollidingRecoveredSources(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+
+	source := writeTestFiles(t, map[string]string{
+		"static/js/main.js":     "(() => { function __webpack_require__(id) {} })();\n",
+		"static/js/main.js.map": `{"version": 3, "file": "main.js", "sources": ["webpack:///./src/app.js"], "sourcesContent": ["console.log('app');"]}`,
+		"recovered/src/app.js":  "console.log('an earlier recovery');\n",
+	})
+
+	recoverSourcesEnabled = true
+	defer func() { recoverSourcesEnabled = false }()
+
+	target := filepath.Join(t.TempDir(), "test-output.zip")
+	if _, err := zipSource(source, target, nil); err == nil {
+		t.Error("expected an error for the colliding recovered source")
+	}
+
+	if _, err := os.Stat(target); !os.IsNotExist(err) {
+		t.Error("the partial archive was not removed")
+	}
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// a convention of a test framework that identifies tests, e.g. the `__mocks__` folders of Jest
+type TestHeuristic struct {
+	// the name of the heuristic, e.g. used by `-disable-test-heuristics` and in the manifest
+	Name string
+	// the frameworks that use the convention
+	Frameworks string
+	Enabled    bool
+	// the names of folders that only contain tests (matched against every segment of a path)
+	Folders []string
+	// the extensions of test files (compared like the ones of `testFileRule`)
+	Extensions []string
+	// the exact names of test files, like `setupTests.js`. A name may include its folder (like `src/test.ts`, the
+	// bootstrap of Angular) if the name alone is too common
+	FileNames []string
+}
+
+// returns the extensions for all flavors of JavaScript and TypeScript, e.g. `.spec.js`, `.spec.mjs`, ..., `.spec.tsx`
+func scriptExtensions(infix string) []string {
+	var extensions []string
+	for _, extension := range []string{"js", "jsx", "mjs", "cjs", "ts", "tsx", "mts", "cts"} {
+		extensions = append(extensions, infix+"."+extension)
+	}
+
+	return extensions
+}
+
+// the maintained catalog of the conventions of the common test frameworks. Entries that are prone to false positives
+// are disabled by default, and can be enabled via `-enable-test-heuristics`
+var testHeuristics = []*TestHeuristic{
+	{Name: "test-folders", Frameworks: "Jest, Mocha, Vitest", Enabled: true, Folders: []string{"test", "tests", "__tests__"}},
+	{Name: "spec-folders", Frameworks: "Jasmine, Mocha", Enabled: true, Folders: []string{"spec", "specs"}},
+	{Name: "e2e-folders", Frameworks: "Protractor, Playwright, Nightwatch", Enabled: true, Folders: []string{"e2e"}},
+	{Name: "spec-files", Frameworks: "Jasmine, Mocha, Jest, Vitest", Enabled: true, Extensions: scriptExtensions(".spec")},
+	{Name: "test-files", Frameworks: "Jest, Mocha, Vitest, node:test", Enabled: true, Extensions: scriptExtensions(".test")},
+	{Name: "e2e-files", Frameworks: "Protractor, Playwright", Enabled: true,
+		Extensions: append(scriptExtensions(".e2e-spec"), scriptExtensions(".e2e")...)},
+	{Name: "mocks", Frameworks: "Jest, Vitest", Enabled: true, Folders: []string{"__mocks__"}},
+	{Name: "fixtures", Frameworks: "Jest, Vitest", Enabled: true, Folders: []string{"__fixtures__", "__snapshots__"},
+		Extensions: []string{".snap"}},
+	{Name: "cypress", Frameworks: "Cypress", Enabled: true, Folders: []string{"cypress"}, Extensions: scriptExtensions(".cy")},
+	{Name: "playwright", Frameworks: "Playwright", Enabled: true, Folders: []string{"playwright"}},
+	{Name: "storybook", Frameworks: "Storybook", Enabled: true, Folders: []string{".storybook"},
+		Extensions: append(scriptExtensions(".stories"), ".stories.mdx", ".stories.svelte", ".stories.vue")},
+	{Name: "test-bootstraps", Frameworks: "Karma, Jest, Vitest", Enabled: true, FileNames: []string{
+		"src/test.ts", "src/test.js", "setupTests.js", "setupTests.ts", "setup-tests.js", "setup-tests.ts", "test-setup.ts",
+		"jest.setup.js", "jest.setup.ts", "vitest.setup.js", "vitest.setup.ts",
+	}},
+	// `testing/` and `test-utils/` may also contain first-party code (like testing utilities that are shipped)
+	{Name: "test-utils", Frameworks: "generic", Enabled: false, Folders: []string{"test-utils", "test-helpers", "testing"}},
+}
+
+// the names of the test heuristics that are enabled by default (to reset them before a run)
+var defaultTestHeuristics = map[string]bool{}
+
+func init() {
+	for _, heuristic := range testHeuristics {
+		defaultTestHeuristics[heuristic.Name] = heuristic.Enabled
+	}
+
+	applyTestHeuristics()
+}
+
+// enables exactly the test heuristics that are enabled by default (undoing `-enable-test-heuristics` and
+// `-disable-test-heuristics` of an earlier run)
+func resetTestHeuristics() {
+	for _, heuristic := range testHeuristics {
+		heuristic.Enabled = defaultTestHeuristics[heuristic.Name]
+	}
+
+	applyTestHeuristics()
+}
+
+// returns the names of all test heuristics
+func GetTestHeuristicNames() []string {
+	var names []string
+	for _, heuristic := range testHeuristics {
+		names = append(names, heuristic.Name)
+	}
+
+	return names
+}
+
+// returns the test heuristic with the provided name (or `nil` if there is none)
+func GetTestHeuristic(name string) *TestHeuristic {
+	for _, heuristic := range testHeuristics {
+		if heuristic.Name == name {
+			return heuristic
+		}
+	}
+
+	return nil
+}
+
+// enables and disables the test heuristics with the provided (comma-separated) names, e.g. `test-utils` and
+// `storybook,spec-folders`
+func SetTestHeuristics(enabled string, disabled string) error {
+	for _, toggle := range []struct {
+		names   string
+		enabled bool
+	}{{enabled, true}, {disabled, false}} {
+		for _, name := range strings.Split(toggle.names, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+
+			heuristic := GetTestHeuristic(name)
+			if heuristic == nil {
+				return fmt.Errorf("unknown test heuristic `%s`", name)
+			}
+
+			heuristic.Enabled = toggle.enabled
+		}
+	}
+
+	applyTestHeuristics()
+	return nil
+}
+
+// the extensions of `testFileRule` are the ones of the enabled test heuristics (so that `-case-sensitive test-files`
+// keeps working)
+func applyTestHeuristics() {
+	testFileRule.Extensions = nil
+	for _, heuristic := range testHeuristics {
+		if heuristic.Enabled {
+			testFileRule.Extensions = append(testFileRule.Extensions, heuristic.Extensions...)
+		}
+	}
+}
+
+// returns the first enabled test heuristic that matches the path (or `nil` if there is none)
+func MatchTestHeuristic(path string) *TestHeuristic {
+	for _, heuristic := range testHeuristics {
+		if heuristic.Enabled && heuristic.Matches(path) {
+			return heuristic
+		}
+	}
+
+	return nil
+}
+
+// check if the path is in a test folder of the heuristic, or is a test file of it
+func (heuristic *TestHeuristic) Matches(path string) bool {
+	return heuristic.MatchesFolder(path) || heuristic.MatchesFile(path)
+}
+
+// check if the path is (or lies in) one of the test folders of the heuristic
+func (heuristic *TestHeuristic) MatchesFolder(path string) bool {
+	return HasPathSegment(path, heuristic.Folders...)
+}
+
+// check if the path is a test file of the heuristic
+func (heuristic *TestHeuristic) MatchesFile(path string) bool {
+	if strings.HasSuffix(path, "/") {
+		return false
+	}
+
+	rule := ExtensionRule{Extensions: heuristic.Extensions, CaseSensitive: testFileRule.CaseSensitive}
+	return rule.Matches(path) || heuristic.MatchesFileName(path)
+}
+
+// check if the path is one of the test files the heuristic knows by name, e.g. `setupTests.js` or `src/test.ts`
+func (heuristic *TestHeuristic) MatchesFileName(path string) bool {
+	for _, fileName := range heuristic.FileNames {
+		if strings.Contains(fileName, "/") {
+			if strings.HasSuffix(NormalizePath(path), "/"+fileName) {
+				return true
+			}
+		} else if HasBaseName(path, fileName) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// returns e.g. "test heuristic `mocks` (Jest, Vitest)"
+func (heuristic *TestHeuristic) String() string {
+	return fmt.Sprintf("test heuristic `%s` (%s)", heuristic.Name, heuristic.Frameworks)
+}
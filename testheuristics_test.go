@@ -0,0 +1,83 @@
+package main
+
+import (
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Tests the conventions of the test heuristics catalog
+func TestTestHeuristics(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+
+	testCases := []struct {
+		path      string
+		heuristic string
+	}{
+		{"/src/app.spec.mjs", "spec-files"},
+		{"/src/app.test.cjs", "test-files"},
+		{"/src/login.cy.ts", "cypress"},
+		{"/cypress/support/commands.js", "cypress"},
+		{"/src/Button.stories.tsx", "storybook"},
+		{"/.storybook/main.js", "storybook"},
+		{"/e2e/app.e2e-spec.ts", "e2e-folders"},
+		{"/src/app.e2e-spec.ts", "e2e-files"},
+		{"/src/api/__mocks__/client.js", "mocks"},
+		{"/src/__fixtures__/user.json", "fixtures"},
+		{"/src/__snapshots__/app.test.js.snap", "fixtures"},
+		{"/playwright/login.ts", "playwright"},
+		{"/spec/helpers/a.js", "spec-folders"},
+		{"/src/test.ts", "test-bootstraps"},
+		{"/projects/admin/src/test.ts", "test-bootstraps"},
+		{"/src/routes/test.ts", ""},
+		{"/test.js", ""},
+		{"/src/setupTests.js", "test-bootstraps"},
+		{"/src/testing/utils.js", ""},
+		{"/src/contest.ts", ""},
+		{"/src/spectrum.js", ""},
+		{"/src/stories.js", ""},
+	}
+
+	for _, testCase := range testCases {
+		heuristic := MatchTestHeuristic(testCase.path)
+		if testCase.heuristic == "" {
+			if heuristic != nil || !isRequired(testCase.path, nil) {
+				t.Errorf("`%s` should not be a test, but matched %v", testCase.path, heuristic)
+			}
+			continue
+		}
+
+		if heuristic == nil || heuristic.Name != testCase.heuristic {
+			t.Errorf("expected `%s` to match the test heuristic `%s`, got %v", testCase.path, testCase.heuristic, heuristic)
+		}
+
+		if isRequired(testCase.path, nil) {
+			t.Errorf("`%s` should be omitted", testCase.path)
+		}
+	}
+}
+
+// Tests that the test heuristics can be enabled and disabled individually
+func TestSetTestHeuristics(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+	defer SetTestHeuristics("spec-folders,storybook", "test-utils")
+
+	if err := SetTestHeuristics("test-utils", "spec-folders, storybook"); err != nil {
+		t.Fatal(err)
+	}
+
+	for path, expected := range map[string]bool{
+		"/spec/helpers/a.js":     true,
+		"/src/Button.stories.js": true,
+		"/src/testing/utils.js":  false,
+		"/src/app.spec.js":       false,
+	} {
+		if got := isRequired(path, nil); got != expected {
+			t.Errorf("`isRequired()` returned %v for `%s`, expected %v", got, path, expected)
+		}
+	}
+
+	if err := SetTestHeuristics("does-not-exist", ""); err == nil {
+		t.Error("expected an error for an unknown test heuristic")
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// how the paths provided via `-tests` are combined with the built-in test heuristics (set via `-tests-mode`)
+const (
+	// only the provided paths are treated as tests (besides test files like `.spec.js`)
+	TestsModeReplace = "replace"
+	// the provided paths are treated as tests in addition to the common test folders and the test runner configs
+	TestsModeExtend = "extend"
+)
+
+var testsMode string = TestsModeReplace
+
+// the values of the repeatable `-tests` flag (e.g. `-tests spec -tests "src/**/__mocks__"`)
+type testsFlag []string
+
+func (f *testsFlag) String() string {
+	return strings.Join(*f, ", ")
+}
+
+func (f *testsFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// sets how the paths of `-tests` are combined with the built-in test heuristics
+func SetTestsMode(mode string) error {
+	if mode != TestsModeReplace && mode != TestsModeExtend {
+		return fmt.Errorf("unknown mode `%s` (expected `%s` or `%s`)", mode, TestsModeReplace, TestsModeExtend)
+	}
+
+	testsMode = mode
+	return nil
+}
+
+// check if the built-in test heuristics (the common test folders and the test runner configs) are used
+func UsesTestHeuristics(testsPaths []string) bool {
+	return len(testsPaths) == 0 || testsMode == TestsModeExtend
+}
+
+// check if the path (like `src/**/__mocks__`) is a glob pattern rather than a folder
+func IsGlob(p string) bool {
+	return strings.ContainsAny(p, "*?[")
+}
+
+// converts the paths provided via `-tests` into the representation used by the rules, e.g. `./spec` becomes `/spec`
+// (and `some\tests` becomes `/some/tests`)
+func NormalizeTestsPaths(testsPaths []string) []string {
+	var normalized []string
+	for _, testsPath := range testsPaths {
+		normalized = append(normalized, path.Join("/", NormalizePath(testsPath)))
+	}
+
+	return normalized
+}
+
+// check that every path provided via `-tests` matches something in the source, the same way the paths are matched
+// later on (see `MatchesTestsPath`). Thus, a folder like `e2e` may exist at any depth (e.g. as `packages/web/e2e`), while
+// globs are anchored at the root of the source
+func ValidateTestsPaths(source string, testsPaths []string) error {
+	var problems []string
+
+	for _, testsPath := range testsPaths {
+		// the common case of a folder in the root of the source does not need a walk
+		if !IsGlob(testsPath) {
+			if _, err := os.Stat(filepath.Join(source, filepath.FromSlash(testsPath))); err == nil {
+				continue
+			}
+		}
+
+		// the walk is stopped as soon as the first match is found
+		errFound := errors.New("found")
+		err := filepath.Walk(source, func(filePath string, info os.FileInfo, err error) error {
+			if err != nil {
+				return nil
+			}
+
+			if info.IsDir() && info.Name() == "node_modules" {
+				return filepath.SkipDir
+			}
+
+			if relPath, err := filepath.Rel(source, filePath); err == nil && MatchesTestsPath("/"+filepath.ToSlash(relPath), testsPath) {
+				return errFound
+			}
+
+			return nil
+		})
+
+		if err != errFound && IsGlob(testsPath) {
+			problems = append(problems, fmt.Sprintf("`%s` does not match anything in `%s`", testsPath, source))
+		} else if err != errFound {
+			problems = append(problems, fmt.Sprintf("`%s` does not exist anywhere in `%s`", testsPath, source))
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, ", "))
+	}
+
+	return nil
+}
+
+// check if the path is matched by a path provided via `-tests`. Folders (like `/test`) match wherever their segments
+// appear in the path, while globs (like `/src/**/__mocks__`) are anchored at the root of the source and match
+// everything below the paths they match
+func MatchesTestsPath(p string, testsPath string) bool {
+	if !IsGlob(testsPath) {
+		return IsInFolder(p, testsPath)
+	}
+
+	return MatchGlob(testsPath, p) || MatchGlob(strings.TrimSuffix(testsPath, "/")+"/**", p)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Tests that several `-tests` paths (and globs) are combined with the heuristics according to `-tests-mode`
+func TestIsInTestFolderWithSeveralPaths(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+	defer func() { testsMode = TestsModeReplace }()
+
+	testsPaths := NormalizeTestsPaths([]string{"./spec", "cypress/", `src\**\__mocks__`})
+
+	testCases := []struct {
+		path     string
+		mode     string
+		expected bool
+	}{
+		{"/spec/a.js", TestsModeReplace, true},
+		{"/cypress/support/commands.js", TestsModeReplace, true},
+		{"/src/app/__mocks__", TestsModeReplace, true},
+		{"/src/app/__mocks__/api.js", TestsModeReplace, true},
+		{"/lib/__mocks__/api.js", TestsModeReplace, false},
+		{"/src/app/api.js", TestsModeReplace, false},
+		{"/test/a.js", TestsModeReplace, false},
+		{"/test/a.js", TestsModeExtend, true},
+		{"/spec/a.js", TestsModeExtend, true},
+		{"/src/app/api.js", TestsModeExtend, false},
+	}
+
+	for _, testCase := range testCases {
+		if err := SetTestsMode(testCase.mode); err != nil {
+			t.Fatal(err)
+		}
+
+		if got := IsInTestFolder(testCase.path, testsPaths); got != testCase.expected {
+			t.Errorf("got %v for `%s` in `%s` mode, expected %v", got, testCase.path, testCase.mode, testCase.expected)
+		}
+	}
+
+	if err := SetTestsMode("merge"); err == nil {
+		t.Error("expected an error for an unknown `-tests-mode`")
+	}
+}
+
+// Tests that the `-tests` paths have to match something in the source
+func TestValidateTestsPaths(t *testing.T) {
+	source := "./sample-projects/sample-node-project"
+
+	if err := ValidateTestsPaths(source, NormalizeTestsPaths([]string{"test", "**/*.test.js"})); err != nil {
+		t.Errorf("expected the paths to be valid, got: %v", err)
+	}
+
+	for _, invalid := range []string{"does-not-exist", "**/*.does-not-exist.js"} {
+		if err := ValidateTestsPaths(source, NormalizeTestsPaths([]string{invalid})); err == nil {
+			t.Errorf("expected an error for `%s`", invalid)
+		}
+	}
+
+	// a folder is matched at any depth, so it is valid as long as it exists somewhere in the source
+	monorepo := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(monorepo, "packages", "web", "e2e"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ValidateTestsPaths(monorepo, NormalizeTestsPaths([]string{"e2e", "web/e2e"})); err != nil {
+		t.Errorf("expected the paths to be valid, got: %v", err)
+	}
+
+	if err := ValidateTestsPaths(monorepo, NormalizeTestsPaths([]string{"app/e2e"})); err == nil {
+		t.Error("expected an error for `app/e2e`")
+	}
+}
@@ -0,0 +1,494 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// the test rules that were read from the configs of the test runners (like `jest.config.js`) of the source that is
+// currently packaged
+var testRunnerRules []*TestRunnerRule
+
+// the test runners whose configs are read, matched by their file names (e.g. `jest.config.ts`)
+var testRunnerConfigs = []struct {
+	runner   string
+	fileName *regexp.Regexp
+}{
+	{"jest", regexp.MustCompile(`^jest\.config\.(js|ts|mjs|cjs|json)$`)},
+	{"vitest", regexp.MustCompile(`^vitest\.config\.(js|ts|mjs|cjs|mts|cts)$`)},
+	{"karma", regexp.MustCompile(`^karma\.conf\.(js|ts|cjs)$`)},
+	{"playwright", regexp.MustCompile(`^playwright\.config\.(js|ts|mjs|cjs|mts|cts)$`)},
+	{"cypress", regexp.MustCompile(`^cypress\.config\.(js|ts|mjs|cjs|mts|cts)$`)},
+}
+
+// the patterns that the test runners use if their config does not specify any
+var defaultTestRunnerPatterns = map[string][]string{
+	"jest":       {"**/__tests__/**/*.[jt]s?(x)", "**/?(*.)+(spec|test).[jt]s?(x)"},
+	"vitest":     {"**/*.{test,spec}.?(c|m)[jt]s?(x)"},
+	"playwright": {"**/*.@(spec|test).?(c|m)[jt]s?(x)"},
+	"cypress":    {"cypress/e2e/**/*.cy.{js,jsx,ts,tsx}"},
+}
+
+// the `files` of Karma that are tests (and not the sources under test)
+var karmaTestFilesRegex = regexp.MustCompile(`(?i)(spec|test)`)
+
+// a rule that identifies tests, as read from the config of a test runner
+type TestRunnerRule struct {
+	Runner string
+	// the config the rule was read from, e.g. `/jest.config.js` or `/package.json`
+	Config string
+	// the setting the rule was read from, e.g. `testMatch` (or `default` if the config does not specify one)
+	Setting string
+	Pattern string
+	// if set, only paths in these folders are tests (e.g. the `roots` of Jest)
+	Roots []string
+	regex *regexp.Regexp
+}
+
+// returns e.g. "`testMatch` `**/*.spec.js` (jest, from `/jest.config.js`)"
+func (rule *TestRunnerRule) String() string {
+	return fmt.Sprintf("`%s` `%s` (%s, from `%s`)", rule.Setting, rule.Pattern, rule.Runner, rule.Config)
+}
+
+// check if the test runner considers the path a test
+func (rule *TestRunnerRule) Matches(p string) bool {
+	if len(rule.Roots) > 0 {
+		inRoot := false
+		for _, root := range rule.Roots {
+			if IsUnder(p, root) {
+				inRoot = true
+				break
+			}
+		}
+
+		if !inRoot {
+			return false
+		}
+	}
+
+	return rule.regex.MatchString(strings.TrimSuffix(p, "/"))
+}
+
+// returns the first test runner rule that matches the path (or `nil` if there is none)
+func MatchTestRunnerRule(p string) *TestRunnerRule {
+	for _, rule := range testRunnerRules {
+		if rule.Matches(p) {
+			return rule
+		}
+	}
+
+	return nil
+}
+
+// check if the path is a test according to the config of a test runner (like `testMatch` in `jest.config.js`)
+func IsTestByRunnerConfig(p string) bool {
+	return MatchTestRunnerRule(p) != nil
+}
+
+// finds the configs of the test runners in the source (outside of `node_modules`) and reads their test rules
+func LoadTestRunnerRules(source string) []*TestRunnerRule {
+	var rules []*TestRunnerRule
+
+	filepath.Walk(source, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+
+		if info.IsDir() && (info.Name() == "node_modules" || info.Name() == ".git") {
+			return filepath.SkipDir
+		}
+
+		if info.IsDir() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(source, filePath)
+		if err != nil {
+			return nil
+		}
+
+		// only the configs are read (i.e. the file name is checked first), not every file of the source
+		var runners []string
+		for _, config := range testRunnerConfigs {
+			if config.fileName.MatchString(info.Name()) {
+				runners = append(runners, config.runner)
+			}
+		}
+
+		if len(runners) == 0 && info.Name() != "package.json" {
+			return nil
+		}
+
+		configPath := "/" + filepath.ToSlash(relPath)
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return nil
+		}
+
+		// Jest may also be configured via the `jest` key of the `package.json`
+		if info.Name() == "package.json" {
+			var packageJson struct {
+				Jest json.RawMessage `json:"jest"`
+			}
+			if json.Unmarshal(content, &packageJson) == nil && len(packageJson.Jest) > 0 {
+				rules = append(rules, parseTestRunnerConfig("jest", configPath, packageJson.Jest)...)
+			}
+
+			return nil
+		}
+
+		for _, runner := range runners {
+			rules = append(rules, parseTestRunnerConfig(runner, configPath, content)...)
+		}
+
+		return nil
+	})
+
+	for _, rule := range rules {
+		log.Info("\tFound the test rule ", rule.String())
+	}
+
+	return rules
+}
+
+// reads the test rules from the config of a test runner. Since most of these configs are JavaScript (which we cannot
+// execute), the string literals of the relevant settings are extracted from it
+func parseTestRunnerConfig(runner string, configPath string, content []byte) []*TestRunnerRule {
+	configDir := path.Dir(configPath)
+	var rules []*TestRunnerRule
+
+	addGlobs := func(setting string, patterns []string, baseDir string, roots []string) {
+		for _, pattern := range patterns {
+			resolved := resolveTestPattern(pattern, configDir, baseDir)
+			regex, err := regexp.Compile(GlobToRegex(resolved))
+			if err != nil {
+				log.Warn("\tCould not parse the pattern `", pattern, "` of `", configPath, "`: ", err)
+				continue
+			}
+
+			rules = append(rules, &TestRunnerRule{Runner: runner, Config: configPath, Setting: setting, Pattern: pattern, Roots: roots, regex: regex})
+		}
+	}
+
+	switch runner {
+	case "jest":
+		var roots []string
+		for _, root := range ExtractConfigStrings(content, "roots") {
+			roots = append(roots, resolveTestPattern(root, configDir, configDir))
+		}
+
+		testRegexes := ExtractConfigStrings(content, "testRegex")
+		for _, pattern := range testRegexes {
+			regex, err := regexp.Compile(pattern)
+			if err != nil {
+				log.Warn("\tCould not parse the `testRegex` `", pattern, "` of `", configPath, "`: ", err)
+				continue
+			}
+
+			rules = append(rules, &TestRunnerRule{Runner: runner, Config: configPath, Setting: "testRegex", Pattern: pattern, Roots: roots, regex: regex})
+		}
+
+		if testMatch := ExtractConfigStrings(content, "testMatch"); len(testMatch) > 0 {
+			addGlobs("testMatch", testMatch, configDir, roots)
+		} else if len(testRegexes) == 0 {
+			addGlobs("default", defaultTestRunnerPatterns[runner], configDir, roots)
+		}
+	case "vitest":
+		// only `test.include` lists the tests: the `include` of e.g. `optimizeDeps` or `test.coverage` lists sources
+		testConfig := ExtractConfigObject(content, "test")
+		for _, key := range []string{"coverage", "benchmark", "typecheck"} {
+			testConfig = RemoveConfigObject(testConfig, key)
+		}
+
+		if include := ExtractConfigStrings(testConfig, "include"); len(include) > 0 {
+			addGlobs("include", include, configDir, nil)
+		} else {
+			addGlobs("default", defaultTestRunnerPatterns[runner], configDir, nil)
+		}
+	case "karma":
+		// the `files` of Karma also contain the sources under test, so only the patterns of tests are taken
+		var testFiles []string
+		for _, pattern := range ExtractConfigStrings(content, "files") {
+			if karmaTestFilesRegex.MatchString(pattern) {
+				testFiles = append(testFiles, pattern)
+			}
+		}
+		addGlobs("files", testFiles, configDir, nil)
+	case "playwright":
+		// everything in an explicit `testDir` belongs to the tests (including fixtures and helpers)
+		testDir := configDir
+		var roots []string
+		if testDirs := ExtractConfigStrings(content, "testDir"); len(testDirs) > 0 {
+			testDir = path.Join(configDir, NormalizePath(testDirs[0]))
+			roots = []string{testDir}
+			addGlobs("testDir", []string{testDirs[0] + "/**"}, configDir, nil)
+		}
+
+		// `testMatch` only applies to the files in `testDir`
+		if testMatch := ExtractConfigStrings(content, "testMatch"); len(testMatch) > 0 {
+			addGlobs("testMatch", testMatch, testDir, roots)
+		} else {
+			addGlobs("default", defaultTestRunnerPatterns[runner], testDir, roots)
+		}
+	case "cypress":
+		if specPattern := ExtractConfigStrings(content, "specPattern"); len(specPattern) > 0 {
+			addGlobs("specPattern", specPattern, configDir, nil)
+		} else {
+			addGlobs("default", defaultTestRunnerPatterns[runner], configDir, nil)
+		}
+	}
+
+	return rules
+}
+
+// resolves a pattern of a test runner config into a pattern relative to the source (starting with a `/`). Jest's
+// `<rootDir>` is the folder of the config, and patterns starting with `**` match anywhere
+func resolveTestPattern(pattern string, configDir string, baseDir string) string {
+	pattern = NormalizePath(pattern)
+
+	if strings.HasPrefix(pattern, "<rootDir>") {
+		return path.Join(configDir, strings.TrimPrefix(pattern, "<rootDir>")) + trailingSlashOf(pattern)
+	}
+
+	if strings.HasPrefix(pattern, "**") {
+		return pattern
+	}
+
+	return path.Join(baseDir, pattern) + trailingSlashOf(pattern)
+}
+
+func trailingSlashOf(p string) string {
+	if strings.HasSuffix(p, "/") && p != "/" {
+		return "/"
+	}
+
+	return ""
+}
+
+// converts a glob as used by the test runners (via micromatch/picomatch) into an anchored regex. Supports `*`, `**`,
+// `?`, character classes, braces (`{js,ts}`), groups (`(js|ts)`) and extglobs (like `?(x)` or `+(spec|test)`)
+func GlobToRegex(glob string) string {
+	var regex strings.Builder
+	// the suffix (like `?` for `?(x)`) of every open group, and whether it was opened by a brace
+	var groupSuffixes []string
+	var groupIsBrace []bool
+
+	regex.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		next := byte(0)
+		if i+1 < len(glob) {
+			next = glob[i+1]
+		}
+
+		switch {
+		case c == '*' && next == '*':
+			i++
+			if i+1 < len(glob) && glob[i+1] == '/' {
+				// `**/` matches zero or more folders
+				i++
+				regex.WriteString("(?:.*/)?")
+			} else {
+				regex.WriteString(".*")
+			}
+		case strings.IndexByte("?*+@!", c) >= 0 && next == '(':
+			i++
+			suffix := map[byte]string{'?': "?", '*': "*", '+': "+", '@': "", '!': ""}[c]
+			groupSuffixes = append(groupSuffixes, suffix)
+			groupIsBrace = append(groupIsBrace, false)
+			regex.WriteString("(?:")
+		case c == '*':
+			regex.WriteString("[^/]*")
+		case c == '?':
+			regex.WriteString("[^/]")
+		case c == '(' || c == '{':
+			groupSuffixes = append(groupSuffixes, "")
+			groupIsBrace = append(groupIsBrace, c == '{')
+			regex.WriteString("(?:")
+		case (c == ')' || c == '}') && len(groupSuffixes) > 0:
+			regex.WriteString(")" + groupSuffixes[len(groupSuffixes)-1])
+			groupSuffixes = groupSuffixes[:len(groupSuffixes)-1]
+			groupIsBrace = groupIsBrace[:len(groupIsBrace)-1]
+		case c == '|' && len(groupSuffixes) > 0:
+			regex.WriteString("|")
+		case c == ',' && len(groupIsBrace) > 0 && groupIsBrace[len(groupIsBrace)-1]:
+			regex.WriteString("|")
+		case c == '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				regex.WriteString(`\[`)
+				continue
+			}
+
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			regex.WriteString("[" + class + "]")
+			i += end + 1
+		case c == '\\' && next != 0:
+			i++
+			regex.WriteString(regexp.QuoteMeta(string(next)))
+		default:
+			regex.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	// unbalanced groups are closed, so that the regex compiles
+	for range groupSuffixes {
+		regex.WriteString(")")
+	}
+
+	regex.WriteString("$")
+	return regex.String()
+}
+
+// extracts the string values of a setting (e.g. `testMatch: ["**/*.spec.js"]` or `"roots": "<rootDir>/src"`) from a
+// JavaScript or JSON config. Supports strings, template literals without expressions, regex literals and arrays of them
+func ExtractConfigStrings(content []byte, key string) []string {
+	keyRegex := regexp.MustCompile(`(?:^|[\s,{])["']?` + regexp.QuoteMeta(key) + `["']?\s*:\s*`)
+	text := string(content)
+
+	var values []string
+	for _, match := range keyRegex.FindAllStringIndex(text, -1) {
+		i := match[1]
+		if i >= len(text) {
+			continue
+		}
+
+		if text[i] != '[' {
+			if value, _, ok := readConfigLiteral(text, i); ok {
+				values = append(values, value)
+			}
+			continue
+		}
+
+		// an array of literals
+		for i++; i < len(text) && text[i] != ']'; {
+			if strings.IndexByte(" \t\r\n,", text[i]) >= 0 {
+				i++
+				continue
+			}
+
+			value, end, ok := readConfigLiteral(text, i)
+			if !ok {
+				break
+			}
+
+			values = append(values, value)
+			i = end
+		}
+	}
+
+	return values
+}
+
+// returns the position of the first object setting (e.g. `coverage: { ... }`) at or after `offset` in a JavaScript or
+// JSON config: the start of its key, its opening brace, and the position after its closing brace
+func findConfigObject(text string, key string, offset int) (int, int, int, bool) {
+	keyRegex := regexp.MustCompile(`(?:^|[\s,{])(["']?` + regexp.QuoteMeta(key) + `["']?\s*:\s*\{)`)
+	match := keyRegex.FindStringSubmatchIndex(text[offset:])
+	if match == nil {
+		return 0, 0, 0, false
+	}
+
+	start, brace := offset+match[2], offset+match[3]-1
+
+	// find the matching closing brace
+	depth, end := 0, len(text)
+	for i := brace; i < len(text); i++ {
+		if text[i] == '{' {
+			depth++
+		} else if text[i] == '}' {
+			depth--
+			if depth == 0 {
+				end = i + 1
+				break
+			}
+		}
+	}
+
+	return start, brace, end, true
+}
+
+// removes an object setting (e.g. `coverage: { ... }`) from a JavaScript or JSON config, so that its nested settings
+// are not mistaken for the ones we are looking for
+func RemoveConfigObject(content []byte, key string) []byte {
+	text := string(content)
+	for {
+		start, _, end, found := findConfigObject(text, key, 0)
+		if !found {
+			return []byte(text)
+		}
+
+		text = text[:start] + text[end:]
+	}
+}
+
+// returns the content of the object settings with the key (e.g. `test: { ... }`) in a JavaScript or JSON config, so
+// that only their nested settings are looked at
+func ExtractConfigObject(content []byte, key string) []byte {
+	text := string(content)
+
+	var objects []string
+	for offset := 0; offset < len(text); {
+		_, brace, end, found := findConfigObject(text, key, offset)
+		if !found {
+			break
+		}
+
+		objects = append(objects, text[brace:end])
+		offset = end
+	}
+
+	return []byte(strings.Join(objects, "\n"))
+}
+
+// reads the string, template or regex literal at position `i`, and returns its (unescaped) value and the position
+// after it
+func readConfigLiteral(text string, i int) (string, int, bool) {
+	quote := text[i]
+	if quote != '"' && quote != '\'' && quote != '`' && quote != '/' {
+		return "", i, false
+	}
+
+	var value strings.Builder
+	for j := i + 1; j < len(text); j++ {
+		c := text[j]
+
+		if c == '\\' && j+1 < len(text) {
+			// regex literals keep their escapes, while strings are unescaped (e.g. `"\\.js$"` becomes `\.js$`)
+			if quote == '/' && text[j+1] != '/' {
+				value.WriteByte(c)
+			}
+			value.WriteByte(text[j+1])
+			j++
+			continue
+		}
+
+		if c == quote {
+			end := j + 1
+			// skip the flags of a regex literal (like `/\.spec\.js$/i`)
+			for quote == '/' && end < len(text) && strings.IndexByte("dgimsuy", text[end]) >= 0 {
+				end++
+			}
+
+			return value.String(), end, !(quote == '`' && strings.Contains(value.String(), "${"))
+		}
+
+		if c == '\n' && quote != '`' {
+			return "", j, false
+		}
+
+		value.WriteByte(c)
+	}
+
+	return "", len(text), false
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Tests the conversion of the globs of the test runners into regexes
+func TestGlobToRegex(t *testing.T) {
+	testCases := []struct {
+		glob    string
+		path    string
+		matches bool
+	}{
+		{"**/__tests__/**/*.[jt]s?(x)", "/src/__tests__/a/b.tsx", true},
+		{"**/__tests__/**/*.[jt]s?(x)", "/src/__tests__.js", false},
+		{"**/?(*.)+(spec|test).[jt]s?(x)", "/src/app.spec.js", true},
+		{"**/?(*.)+(spec|test).[jt]s?(x)", "/test.ts", true},
+		{"**/?(*.)+(spec|test).[jt]s?(x)", "/src/contest.js", false},
+		{"/src/**/*.{test,spec}.?(c|m)[jt]s?(x)", "/src/a/b.test.mjs", true},
+		{"/src/**/*.{test,spec}.?(c|m)[jt]s?(x)", "/lib/b.test.mjs", false},
+		{"/(tests/unit/**/*.spec.(js|ts)|**/__tests__/*.(js|ts))", "/tests/unit/a.spec.js", true},
+		{"/(tests/unit/**/*.spec.(js|ts)|**/__tests__/*.(js|ts))", "/src/__tests__/a.ts", true},
+		{"/(tests/unit/**/*.spec.(js|ts)|**/__tests__/*.(js|ts))", "/src/a.spec.js", false},
+	}
+
+	for _, testCase := range testCases {
+		regex := regexp.MustCompile(GlobToRegex(testCase.glob))
+		if regex.MatchString(testCase.path) != testCase.matches {
+			t.Errorf("expected `%s` matching `%s` to be %v (regex: %s)", testCase.glob, testCase.path, testCase.matches, regex)
+		}
+	}
+}
+
+// Tests that the values of settings are extracted from JavaScript and JSON configs
+func TestExtractConfigStrings(t *testing.T) {
+	content := []byte(`module.exports = {
+		roots: ['<rootDir>/src'],
+		"testMatch": ["**/*.spec.js", ` + "`**/*.test.js`" + `],
+		testRegex: /(\/__tests__\/.*|\.test)\.jsx?$/i,
+		mytestMatch: "not/this",
+	}`)
+
+	testCases := map[string][]string{
+		"roots":     {"<rootDir>/src"},
+		"testMatch": {"**/*.spec.js", "**/*.test.js"},
+		// the escaped slashes of regex literals are unescaped, since Go does not need them
+		"testRegex": {`(/__tests__/.*|\.test)\.jsx?$`},
+	}
+
+	for key, expected := range testCases {
+		if got := ExtractConfigStrings(content, key); !reflect.DeepEqual(got, expected) {
+			t.Errorf("Got: %v", got)
+			t.Errorf("Expected: %v", expected)
+		}
+	}
+}
+
+// Tests that only the nested settings of an object setting are extracted
+func TestExtractConfigObject(t *testing.T) {
+	content := []byte(`export default defineConfig({
+		optimizeDeps: { include: ["lodash"] },
+		latest: { include: ["not/this"] },
+		test: { include: ["src/**/*.unit.ts"], coverage: { include: ["src/**"] } },
+	})`)
+
+	testConfig := ExtractConfigObject(content, "test")
+	if got := ExtractConfigStrings(testConfig, "include"); !reflect.DeepEqual(got, []string{"src/**/*.unit.ts", "src/**"}) {
+		t.Errorf("Got: %v", got)
+	}
+
+	if got := ExtractConfigStrings(RemoveConfigObject(testConfig, "coverage"), "include"); !reflect.DeepEqual(got, []string{"src/**/*.unit.ts"}) {
+		t.Errorf("Got: %v", got)
+	}
+
+	if got := ExtractConfigObject(content, "missing"); len(got) != 0 {
+		t.Errorf("Got: %s", got)
+	}
+}
+
+// Tests that the configs of the test runners decide which files are tests
+func TestTestRunnerRules(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+
+	source := writeTestFiles(t, map[string]string{
+		"packages/api/jest.config.js": `module.exports = { roots: ["<rootDir>/src"], testMatch: ["**/*.check.js"] };`,
+		"packages/web/package.json":   `{"name": "web", "jest": {"testRegex": "/checks/.*\\.js$"}}`,
+		"vitest.config.ts": `export default defineConfig({
+			optimizeDeps: { include: ["vendor/**"] },
+			test: {
+				include: ["src/**/*.unit.ts"],
+				coverage: { include: ["src/**"] },
+			},
+		})`,
+		"playwright.config.ts": `export default defineConfig({ testDir: "./integration" })`,
+		"cypress.config.js":    `module.exports = { e2e: { specPattern: "ui-tests/**/*.cy.js" } }`,
+	})
+
+	testRunnerRules = LoadTestRunnerRules(source)
+	defer func() { testRunnerRules = nil }()
+
+	testCases := map[string]string{
+		"/packages/api/src/a.check.js":    "packages/api/jest.config.js",
+		"/packages/api/other/a.check.js":  "",
+		"/packages/web/checks/a.js":       "packages/web/package.json",
+		"/src/a.unit.ts":                  "vitest.config.ts",
+		"/src/a.ts":                       "",
+		"/vendor/chart.js":                "",
+		"/integration/fixtures/user.json": "playwright.config.ts",
+		"/src/app.spec.ts":                "",
+		"/ui-tests/login.cy.js":           "cypress.config.js",
+	}
+
+	for path, expectedConfig := range testCases {
+		rule := MatchTestRunnerRule(path)
+		if expectedConfig == "" {
+			if rule != nil {
+				t.Errorf("`%s` should not be a test, but matched %s", path, rule.String())
+			}
+			continue
+		}
+
+		if rule == nil || rule.Config != "/"+expectedConfig {
+			t.Errorf("expected `%s` to be a test according to `%s`, got %+v", path, expectedConfig, rule)
+		}
+
+		if decision := evaluatePath(path, nil); decision.Rule != "test-runners" || len(decision.Notes) == 0 {
+			t.Errorf("expected `%s` to be omitted by the `test-runners` rule, got %+v", path, decision)
+		}
+	}
+}
@@ -0,0 +1,383 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// the TypeScript projects (i.e., `tsconfig*.json` files) of the source that is currently packaged. If there are none,
+// `.d.ts` files and `tsconfig.json` files are simply omitted (see `IsMiscNotRequiredFile()`)
+var tsProjects []*TsProject
+
+// the maximum depth of an `extends` chain (protects against cycles)
+const maxTsConfigExtendsDepth = 10
+
+// matches the names of `tsconfig` files that belong to tests, like `tsconfig.spec.json` or `tsconfig.e2e.json`
+var tsTestConfigRegex = regexp.MustCompile(`^tsconfig\.(spec|specs|test|tests|unit|e2e|jest|karma|vitest|cypress|playwright)\.json$`)
+
+// a TypeScript project, as defined by a `tsconfig*.json` file (with its `extends` chain resolved). All paths are relative
+// to the source, start with a `/`, and use forward slashes (e.g. `/src/**/*.spec.ts`)
+type TsProject struct {
+	ConfigPath string
+	// `true` for projects that only contain tests, like `tsconfig.spec.json`
+	IsTest  bool
+	Files   []string
+	Include []string
+	Exclude []string
+	RootDir string
+	// `true` if the project has neither `files` nor `include`, i.e. TypeScript includes everything in its folder
+	IncludesEverything bool
+	// where the compiler writes its output (including generated `.d.ts` files)
+	OutDirs []string
+}
+
+// the parts of a `tsconfig.json` that we need
+type tsConfig struct {
+	Extends         json.RawMessage `json:"extends"`
+	Files           *[]string       `json:"files"`
+	Include         *[]string       `json:"include"`
+	Exclude         *[]string       `json:"exclude"`
+	CompilerOptions struct {
+		RootDir        string `json:"rootDir"`
+		OutDir         string `json:"outDir"`
+		DeclarationDir string `json:"declarationDir"`
+	} `json:"compilerOptions"`
+	References []struct {
+		Path string `json:"path"`
+	} `json:"references"`
+}
+
+// matches comments (and strings, so that e.g. `"src/**/*"` is not mistaken for the start of a comment)
+var jsoncCommentRegex = regexp.MustCompile(`("(?:[^"\\]|\\.)*")|//[^\n]*|/\*[\s\S]*?\*/`)
+
+// matches trailing commas, like in `["a", "b",]`
+var jsoncTrailingCommaRegex = regexp.MustCompile(`("(?:[^"\\]|\\.)*")|,(\s*[}\]])`)
+
+// converts JSON with comments and trailing commas (as allowed in `tsconfig.json`) into plain JSON
+func StripJsonComments(content []byte) []byte {
+	content = jsoncCommentRegex.ReplaceAll(content, []byte("$1"))
+	return jsoncTrailingCommaRegex.ReplaceAll(content, []byte("$1$2"))
+}
+
+// finds and parses all `tsconfig*.json` files (and the projects they reference) of the source
+func LoadTsProjects(source string) []*TsProject {
+	var projects []*TsProject
+	loaded := map[string]bool{}
+
+	var load func(configPath string)
+	load = func(configPath string) {
+		if loaded[configPath] {
+			return
+		}
+		loaded[configPath] = true
+
+		project, references, err := loadTsProject(source, configPath)
+		if err != nil {
+			log.Warn("\tCould not parse `", configPath, "`: ", err)
+			return
+		}
+
+		projects = append(projects, project)
+		for _, reference := range references {
+			load(reference)
+		}
+	}
+
+	filepath.Walk(source, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+
+		if info.IsDir() && (info.Name() == "node_modules" || info.Name() == ".git") {
+			return filepath.SkipDir
+		}
+
+		if !info.IsDir() && MatchGlob("tsconfig*.json", info.Name()) {
+			if relPath, err := filepath.Rel(source, filePath); err == nil {
+				load("/" + filepath.ToSlash(relPath))
+			}
+		}
+
+		return nil
+	})
+
+	for _, project := range projects {
+		log.Info("\tFound the TypeScript project `", project.ConfigPath, "` (test project: ", project.IsTest, ")")
+	}
+
+	return projects
+}
+
+// parses a `tsconfig*.json` file (e.g. `/src/tsconfig.app.json`) and resolves its `extends` chain. Also returns the
+// paths of the configs of the referenced projects
+func loadTsProject(source string, configPath string) (*TsProject, []string, error) {
+	project := &TsProject{ConfigPath: configPath, IsTest: tsTestConfigRegex.MatchString(BaseName(configPath))}
+
+	config, err := readTsConfig(source, configPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// the chain, starting at the config itself, followed by the config it extends, and so on
+	chain := []*tsConfig{config}
+	chainPaths := []string{configPath}
+	for depth := 0; depth < maxTsConfigExtendsDepth; depth++ {
+		var nextPaths []string
+		current := chain[len(chain)-1]
+
+		// `extends` may be a string or (since TypeScript 5.0) an array of strings
+		var extends []string
+		var single string
+		if json.Unmarshal(current.Extends, &single) == nil && single != "" {
+			extends = []string{single}
+		} else {
+			json.Unmarshal(current.Extends, &extends)
+		}
+
+		for _, extended := range extends {
+			if extendedPath := resolveTsConfigPath(source, chainPaths[len(chainPaths)-1], extended); extendedPath != "" {
+				nextPaths = append(nextPaths, extendedPath)
+			}
+		}
+
+		// NOTE: Only the first extended config is followed for arrays. This is good enough to learn `include`, `exclude`,
+		// `files` and the output folders
+		if len(nextPaths) == 0 {
+			break
+		}
+
+		extendedConfig, err := readTsConfig(source, nextPaths[0])
+		if err != nil {
+			break
+		}
+
+		chain = append(chain, extendedConfig)
+		chainPaths = append(chainPaths, nextPaths[0])
+	}
+
+	// the first config in the chain that defines a setting wins. Paths are relative to the config that defines them
+	for i, current := range chain {
+		configDir := path.Dir(chainPaths[i])
+
+		if project.Files == nil && current.Files != nil {
+			project.Files = resolveTsPaths(configDir, *current.Files, false)
+		}
+
+		if project.Include == nil && current.Include != nil {
+			project.Include = resolveTsPaths(configDir, *current.Include, true)
+		}
+
+		if project.Exclude == nil && current.Exclude != nil {
+			project.Exclude = resolveTsPaths(configDir, *current.Exclude, true)
+		}
+
+		if project.RootDir == "" && current.CompilerOptions.RootDir != "" {
+			project.RootDir = path.Join(configDir, NormalizePath(current.CompilerOptions.RootDir))
+		}
+
+		for _, outDir := range []string{current.CompilerOptions.OutDir, current.CompilerOptions.DeclarationDir} {
+			if outDir != "" {
+				project.OutDirs = append(project.OutDirs, path.Join(configDir, NormalizePath(outDir)))
+			}
+		}
+	}
+
+	// without `files` and `include`, TypeScript includes everything in the folder of the config
+	if project.Files == nil && project.Include == nil {
+		project.Include = []string{path.Join(path.Dir(configPath), "**")}
+		project.IncludesEverything = true
+	}
+
+	var references []string
+	for _, reference := range config.References {
+		if referencePath := resolveTsConfigPath(source, configPath, reference.Path); referencePath != "" {
+			references = append(references, referencePath)
+		}
+	}
+
+	return project, references, nil
+}
+
+func readTsConfig(source string, configPath string) (*tsConfig, error) {
+	content, err := os.ReadFile(filepath.Join(source, filepath.FromSlash(strings.TrimPrefix(configPath, "/"))))
+	if err != nil {
+		return nil, err
+	}
+
+	var config tsConfig
+	if err := json.Unmarshal(StripJsonComments(content), &config); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// resolves the path of an extended or referenced config (like `../tsconfig.json`, `./packages/api` or
+// `@tsconfig/node16/tsconfig.json`), relative to the config that extends/references it. Returns "" if it does not exist
+func resolveTsConfigPath(source string, fromConfigPath string, reference string) string {
+	reference = NormalizePath(reference)
+
+	var candidate string
+	if strings.HasPrefix(reference, ".") || strings.HasPrefix(reference, "/") {
+		candidate = path.Join(path.Dir(fromConfigPath), reference)
+	} else {
+		// a config from a package, which is resolved from the `node_modules` in the root of the source
+		candidate = path.Join("/node_modules", reference)
+	}
+
+	for _, possiblePath := range []string{candidate, candidate + ".json", path.Join(candidate, "tsconfig.json")} {
+		info, err := os.Stat(filepath.Join(source, filepath.FromSlash(strings.TrimPrefix(possiblePath, "/"))))
+		if err == nil && !info.IsDir() {
+			return possiblePath
+		}
+	}
+
+	return ""
+}
+
+// resolves the paths of `files`, `include` or `exclude` relative to the folder of the config. Patterns of folders (like
+// `src`) match everything in the folder
+func resolveTsPaths(configDir string, paths []string, arePatterns bool) []string {
+	resolved := []string{}
+
+	for _, p := range paths {
+		p = path.Join(configDir, NormalizePath(p))
+
+		base := BaseName(p)
+		if arePatterns && !strings.ContainsAny(base, "*?") && !strings.Contains(base, ".") {
+			p += "/**"
+		}
+
+		resolved = append(resolved, p)
+	}
+
+	return resolved
+}
+
+// check if the path is explicitly part of the project, i.e. listed in `files` or matched by `include` (and not `exclude`)
+func (project *TsProject) Contains(p string) bool {
+	for _, file := range project.Files {
+		if file == p {
+			return true
+		}
+	}
+
+	// TypeScript never picks up its own output (or anything outside of `rootDir`) via `include`
+	if project.IsOutput(p) || (project.RootDir != "" && !IsUnder(p, project.RootDir)) {
+		return false
+	}
+
+	for _, exclude := range project.Exclude {
+		if MatchGlob(exclude, p) {
+			return false
+		}
+	}
+
+	for _, include := range project.Include {
+		if MatchGlob(include, p) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// check if the path lies in the output folder of the project (i.e., was generated by the compiler)
+func (project *TsProject) IsOutput(p string) bool {
+	for _, outDir := range project.OutDirs {
+		if IsUnder(p, outDir) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// check if the `.ts` file only belongs to test projects (like `tsconfig.spec.json`), or if the `.d.ts` file or the
+// `tsconfig` belongs to tests or was generated by the compiler
+func IsOmittedByTsProjects(p string) bool {
+	if len(tsProjects) == 0 || strings.HasSuffix(p, "/") {
+		return false
+	}
+
+	for _, project := range tsProjects {
+		if project.ConfigPath == p {
+			return project.IsTest
+		}
+	}
+
+	if HasExtension(p, ".d.ts") {
+		return isGeneratedDeclaration(p)
+	}
+
+	if !HasExtension(p, ".ts", ".tsx", ".mts", ".cts") {
+		return false
+	}
+
+	// a base config that includes everything (like the root `tsconfig.json` of an Angular app) does not tell anything
+	// about whether a file belongs to the tests
+	inTestProject := false
+	for _, project := range tsProjects {
+		if !project.Contains(p) || (project.IncludesEverything && !project.IsTest) {
+			continue
+		}
+
+		if !project.IsTest {
+			return false
+		}
+
+		inTestProject = true
+	}
+
+	return inTestProject
+}
+
+// check if the `.d.ts` file or `tsconfig` is first-party source that we want to keep, even though such files are
+// usually omitted. This is the case for hand-written declarations in a project, and for the configs of non-test projects
+// (as they e.g. contain the path aliases)
+func IsKeptByTsProjects(p string) bool {
+	if len(tsProjects) == 0 {
+		return false
+	}
+
+	for _, project := range tsProjects {
+		if project.ConfigPath == p {
+			return !project.IsTest
+		}
+	}
+
+	if !HasExtension(p, ".d.ts") || isGeneratedDeclaration(p) {
+		return false
+	}
+
+	for _, project := range tsProjects {
+		if project.Contains(p) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// check if a `.d.ts` file was generated, i.e. lies in an output folder or next to the `.js` file it was generated for
+func isGeneratedDeclaration(p string) bool {
+	for _, project := range tsProjects {
+		if project.IsOutput(p) {
+			return true
+		}
+	}
+
+	if jsPath := strings.TrimSuffix(p, ".d.ts") + ".js"; sourceRoot != "" {
+		if _, err := os.Stat(filepath.Join(sourceRoot, filepath.FromSlash(strings.TrimPrefix(jsPath, "/")))); err == nil {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Tests that comments and trailing commas are removed from a `tsconfig.json`, but not from strings
+func TestStripJsonComments(t *testing.T) {
+	content := `{
+		// a comment
+		"include": ["src/**/*", "http://example.com"], /* another comment */
+		"exclude": ["dist",],
+	}`
+
+	expected := `{"include": ["src/**/*", "http://example.com"], "exclude": ["dist"]}`
+
+	var got, want map[string][]string
+	if err := json.Unmarshal(StripJsonComments([]byte(content)), &got); err != nil {
+		t.Fatal(err)
+	}
+
+	json.Unmarshal([]byte(expected), &want)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Got: %v", got)
+		t.Errorf("Expected: %v", want)
+	}
+}
+
+// Tests that the TypeScript projects (with `extends` and `references`) decide which `.ts`/`.d.ts` files are kept
+func TestTsProjectRules(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+
+	source := writeTestFiles(t, map[string]string{
+		"tsconfig.json": `{
+			// the solution config
+			"files": [],
+			"references": [{"path": "./configs/app.json"}, {"path": "./tsconfig.spec.json"}],
+		}`,
+		"tsconfig.base.json":      `{"compilerOptions": {"outDir": "./out", "declarationDir": "./types"}}`,
+		"configs/app.json":        `{"extends": "../tsconfig.base.json", "include": ["../src"], "exclude": ["../src/**/*.spec.ts", "../src/karma-entry.ts"]}`,
+		"tsconfig.spec.json":      `{"extends": ["./tsconfig.base.json"], "include": ["src/**/*.spec.ts", "src/karma-entry.ts"]}`,
+		"src/app.ts":              "",
+		"src/app.spec.ts":         "",
+		"src/karma-entry.ts":      "",
+		"src/global.d.ts":         "",
+		"src/legacy.js":           "",
+		"src/legacy.d.ts":         "",
+		"out/app.d.ts":            "",
+		"types/app.d.ts":          "",
+		"node_modules/x/index.ts": "",
+	})
+
+	sourceRoot = source
+	tsProjects = LoadTsProjects(source)
+	defer func() { sourceRoot, tsProjects = "", nil }()
+
+	if len(tsProjects) != 4 {
+		t.Fatalf("expected 4 TypeScript projects, got %d", len(tsProjects))
+	}
+
+	testCases := map[string]string{
+		"/src/app.ts":          "",
+		"/src/global.d.ts":     "",
+		"/configs/app.json":    "",
+		"/tsconfig.json":       "",
+		"/src/karma-entry.ts":  "typescript",
+		"/tsconfig.spec.json":  "typescript",
+		"/src/legacy.d.ts":     "typescript",
+		"/out/app.d.ts":        "typescript",
+		"/types/app.d.ts":      "typescript",
+		"/src/app.spec.ts":     testFileRule.Name,
+		"/tsconfig.base.json":  "",
+		"/some/other/thing.ts": "",
+	}
+
+	for path, expected := range testCases {
+		if decision := evaluatePath(path, nil); decision.Rule != expected {
+			t.Errorf("expected `%s` to be omitted by the rule `%s`, got %+v", path, expected, decision)
+		}
+	}
+}
+
+// Tests that `.d.ts` files and `tsconfig.json` are still omitted if there are no TypeScript projects
+func TestTsProjectRulesWithoutProjects(t *testing.T) {
+	for _, path := range []string{"/src/global.d.ts", "/tsconfig.json"} {
+		if isRequired(path, nil) {
+			t.Errorf("`%s` should be omitted", path)
+		}
+	}
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// the base URL of the Veracode XML APIs (may be changed via `-api-base-url`, e.g. for another region or a stub server)
+const defaultVeracodeBaseURL = "https://analysiscenter.veracode.com"
+
+// the environment variables that contain the Veracode API credentials
+const (
+	veracodeAPIKeyIDEnv     = "VERACODE_API_KEY_ID"
+	veracodeAPIKeySecretEnv = "VERACODE_API_KEY_SECRET"
+)
+
+// the name of the Veracode HMAC authentication scheme, and the version of the request signing
+const (
+	veracodeAuthScheme     = "VERACODE-HMAC-SHA-256"
+	veracodeRequestVersion = "vcode_request_version_1"
+)
+
+// a client for the parts of the Veracode XML APIs needed to upload an archive
+type VeracodeClient struct {
+	BaseURL    string
+	KeyID      string
+	KeySecret  string
+	HTTPClient *http.Client
+}
+
+// creates a client with the credentials from `VERACODE_API_KEY_ID` and `VERACODE_API_KEY_SECRET`
+func NewVeracodeClientFromEnv(baseURL string) (*VeracodeClient, error) {
+	keyID := os.Getenv(veracodeAPIKeyIDEnv)
+	keySecret := os.Getenv(veracodeAPIKeySecretEnv)
+	if keyID == "" || keySecret == "" {
+		return nil, fmt.Errorf("`%s` and `%s` have to be set", veracodeAPIKeyIDEnv, veracodeAPIKeySecretEnv)
+	}
+
+	if baseURL == "" {
+		baseURL = defaultVeracodeBaseURL
+	}
+
+	return &VeracodeClient{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		KeyID:      keyID,
+		KeySecret:  keySecret,
+		HTTPClient: &http.Client{Timeout: 10 * time.Minute},
+	}, nil
+}
+
+// returns the `Authorization` header of Veracode's HMAC scheme for a request. The nonce is random and the timestamp is
+// in milliseconds. Credentials of other regions (like `vera01ei-...`) are used without their prefix
+func GetVeracodeAuthorization(keyID string, keySecret string, method string, requestURL *url.URL, timestamp int64, nonce []byte) (string, error) {
+	keyID = withoutRegionPrefix(keyID)
+	secret, err := hex.DecodeString(withoutRegionPrefix(keySecret))
+	if err != nil {
+		return "", fmt.Errorf("`%s` is not a valid API key secret: %w", veracodeAPIKeySecretEnv, err)
+	}
+
+	path := requestURL.EscapedPath()
+	if requestURL.RawQuery != "" {
+		path += "?" + requestURL.RawQuery
+	}
+
+	data := fmt.Sprintf("id=%s&host=%s&url=%s&method=%s", keyID, requestURL.Host, path, method)
+	ts := fmt.Sprintf("%d", timestamp)
+
+	// the signing key is derived from the secret, the nonce, the timestamp and the request version
+	signingKey := hmacSHA256(hmacSHA256(hmacSHA256(secret, nonce), []byte(ts)), []byte(veracodeRequestVersion))
+	signature := hex.EncodeToString(hmacSHA256(signingKey, []byte(data)))
+
+	return fmt.Sprintf("%s id=%s,ts=%s,nonce=%s,sig=%s", veracodeAuthScheme, keyID, ts, hex.EncodeToString(nonce), signature), nil
+}
+
+func hmacSHA256(key []byte, data []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+func withoutRegionPrefix(credential string) string {
+	if index := strings.LastIndex(credential, "-"); index >= 0 {
+		return credential[index+1:]
+	}
+
+	return credential
+}
+
+// sends a signed request to an endpoint of the XML API (like `getapplist.do`), and returns the body of the response
+func (client *VeracodeClient) do(method string, endpoint string, query url.Values, body io.Reader, contentType string) ([]byte, error) {
+	requestURL, err := url.Parse(client.BaseURL + "/api/5.0/" + endpoint)
+	if err != nil {
+		return nil, err
+	}
+	requestURL.RawQuery = query.Encode()
+
+	request, err := http.NewRequest(method, requestURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	authorization, err := GetVeracodeAuthorization(client.KeyID, client.KeySecret, method, requestURL, time.Now().UnixMilli(), nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	request.Header.Set("Authorization", authorization)
+	if contentType != "" {
+		request.Header.Set("Content-Type", contentType)
+	}
+
+	response, err := client.HTTPClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	content, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("`%s` returned %s: %s", endpoint, response.Status, strings.TrimSpace(string(content)))
+	}
+
+	// the XML API reports errors with a status of 200 and an `<error>` element
+	var apiError struct {
+		XMLName xml.Name `xml:"error"`
+		Message string   `xml:",chardata"`
+	}
+	if xml.Unmarshal(content, &apiError) == nil {
+		return nil, fmt.Errorf("`%s` failed: %s", endpoint, strings.TrimSpace(apiError.Message))
+	}
+
+	return content, nil
+}
+
+// returns the ID of the application profile with the provided name
+func (client *VeracodeClient) GetAppID(appName string) (string, error) {
+	content, err := client.do(http.MethodGet, "getapplist.do", url.Values{}, nil, "")
+	if err != nil {
+		return "", err
+	}
+
+	var appList struct {
+		Apps []struct {
+			ID   string `xml:"app_id,attr"`
+			Name string `xml:"app_name,attr"`
+		} `xml:"app"`
+	}
+	if err := xml.Unmarshal(content, &appList); err != nil {
+		return "", fmt.Errorf("could not parse the application list: %w", err)
+	}
+
+	for _, app := range appList.Apps {
+		if app.Name == appName {
+			return app.ID, nil
+		}
+	}
+
+	return "", fmt.Errorf("no application profile named `%s` was found", appName)
+}
+
+// uploads a file to the application profile. The file is streamed into the request (instead of being read into memory
+// first), since e.g. an archive for the Pipeline Scan may have up to 200 MB
+func (client *VeracodeClient) UploadFile(appID string, filePath string) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	bodyReader, bodyWriter := io.Pipe()
+	writer := multipart.NewWriter(bodyWriter)
+
+	written := make(chan error, 1)
+	go func() {
+		err := writeMultipartFile(writer, "file", filepath.Base(filePath), file)
+		bodyWriter.CloseWithError(err)
+		written <- err
+	}()
+
+	_, err = client.do(http.MethodPost, "uploadfile.do", url.Values{"app_id": {appID}}, bodyReader, writer.FormDataContentType())
+
+	// if the request failed before the whole body was sent, this stops the writing (before the file is closed)
+	bodyReader.Close()
+	<-written
+
+	return err
+}
+
+// writes the file as the only part of the multipart body, and finishes the body
+func writeMultipartFile(writer *multipart.Writer, fieldName string, fileName string, content io.Reader) error {
+	part, err := writer.CreateFormFile(fieldName, fileName)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(part, content); err != nil {
+		return err
+	}
+
+	return writer.Close()
+}
+
+// starts the prescan of the uploaded files. With `autoScan`, the scan starts right after a successful prescan
+func (client *VeracodeClient) BeginPrescan(appID string, autoScan bool) error {
+	query := url.Values{"app_id": {appID}, "auto_scan": {fmt.Sprintf("%t", autoScan)}}
+	_, err := client.do(http.MethodPost, "beginprescan.do", query, nil, "")
+	return err
+}
+
+// uploads the archive to the application profile with the provided name, and optionally starts the prescan
+func UploadArchive(client *VeracodeClient, appName string, archivePath string, prescan bool) error {
+	if appName == "" {
+		return errors.New("no application profile was provided (via `-app`)")
+	}
+
+	log.Info("\tLooking up the application profile `", appName, "`")
+	appID, err := client.GetAppID(appName)
+	if err != nil {
+		return err
+	}
+
+	log.Info("\tUploading `", archivePath, "` to the application profile `", appName, "` (ID: ", appID, ")")
+	if err := client.UploadFile(appID, archivePath); err != nil {
+		return err
+	}
+
+	if prescan {
+		log.Info("\tStarting the prescan (the scan will start automatically afterwards)")
+		if err := client.BeginPrescan(appID, true); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
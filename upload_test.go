@@ -0,0 +1,175 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const testKeyID = "3ddaeeb10ca690df3fee5e3bd1c329fa"
+const testKeySecret = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+// Tests the HMAC scheme against known answers (computed independently of the packager, following the reference of the
+// Veracode API signing)
+func TestGetVeracodeAuthorization(t *testing.T) {
+	testCases := []struct {
+		keyID     string
+		keySecret string
+		method    string
+		url       string
+		timestamp int64
+		nonce     string
+		expected  string
+	}{
+		{
+			testKeyID, testKeySecret, "GET",
+			"https://analysiscenter.veracode.com/api/5.0/getapplist.do?include_user_info=true",
+			1700000000000, "cf8a59e2b0b8fd21a7d0e8e34b7f0a40",
+			"VERACODE-HMAC-SHA-256 id=3ddaeeb10ca690df3fee5e3bd1c329fa,ts=1700000000000,nonce=cf8a59e2b0b8fd21a7d0e8e34b7f0a40," +
+				"sig=23f45a75df2e8c435d800581703e50fa42556c1f4a8f57511c50cf77daba37a4",
+		},
+		// credentials of other regions have a prefix, which is not part of the signature
+		{
+			"vera01ei-" + testKeyID, "vera01es-" + testKeySecret, "POST",
+			"https://analysiscenter.veracode.eu/api/5.0/uploadfile.do?app_id=1337",
+			1700000123456, "00112233445566778899aabbccddeeff",
+			"VERACODE-HMAC-SHA-256 id=3ddaeeb10ca690df3fee5e3bd1c329fa,ts=1700000123456,nonce=00112233445566778899aabbccddeeff," +
+				"sig=352842f70bd777468fc0342053a86a27311e74b26c64b9a97238f5a1ea16d82c",
+		},
+	}
+
+	for _, testCase := range testCases {
+		requestURL, _ := url.Parse(testCase.url)
+		nonce, _ := hex.DecodeString(testCase.nonce)
+
+		authorization, err := GetVeracodeAuthorization(testCase.keyID, testCase.keySecret, testCase.method, requestURL, testCase.timestamp, nonce)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if authorization != testCase.expected {
+			t.Errorf("Got: %s", authorization)
+			t.Errorf("Expected: %s", testCase.expected)
+		}
+	}
+
+	requestURL, _ := url.Parse("https://analysiscenter.veracode.com/api/5.0/getapplist.do")
+	if _, err := GetVeracodeAuthorization(testKeyID, "not-hex", "GET", requestURL, 1700000000000, []byte{0}); err == nil {
+		t.Error("expected an error for an invalid secret")
+	}
+}
+
+// checks that a request carries an `Authorization` header of the HMAC scheme for the test credentials (the signature
+// itself is covered by the known answers of `TestGetVeracodeAuthorization`)
+var veracodeAuthorizationRegex = regexp.MustCompile(`^VERACODE-HMAC-SHA-256 id=` + testKeyID + `,ts=\d{13},nonce=[0-9a-f]{32},sig=[0-9a-f]{64}$`)
+
+func verifyVeracodeAuthorization(r *http.Request) error {
+	if !veracodeAuthorizationRegex.MatchString(r.Header.Get("Authorization")) {
+		return fmt.Errorf("invalid authorization: %s", r.Header.Get("Authorization"))
+	}
+
+	return nil
+}
+
+// Integration test for the upload against a stub of the Veracode API
+func TestUploadArchive(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+
+	var calls []string
+	var uploaded string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := verifyVeracodeAuthorization(r); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		calls = append(calls, r.URL.Path)
+		switch r.URL.Path {
+		case "/api/5.0/getapplist.do":
+			fmt.Fprint(w, `<applist><app app_id="42" app_name="other-app"/><app app_id="1337" app_name="my-app"/></applist>`)
+		case "/api/5.0/uploadfile.do":
+			file, _, err := r.FormFile("file")
+			if err != nil || r.URL.Query().Get("app_id") != "1337" {
+				fmt.Fprint(w, `<error>invalid upload</error>`)
+				return
+			}
+			content, _ := io.ReadAll(file)
+			uploaded = string(content)
+			fmt.Fprint(w, `<filelist app_id="1337"/>`)
+		case "/api/5.0/beginprescan.do":
+			fmt.Fprint(w, `<buildinfo app_id="1337"/>`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv(veracodeAPIKeyIDEnv, testKeyID)
+	t.Setenv(veracodeAPIKeySecretEnv, testKeySecret)
+
+	client, err := NewVeracodeClientFromEnv(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	archivePath := filepath.Join(t.TempDir(), "vc-output.zip")
+	if err := os.WriteFile(archivePath, []byte("zip content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := UploadArchive(client, "my-app", archivePath, true); err != nil {
+		t.Fatal(err)
+	}
+
+	if uploaded != "zip content" {
+		t.Errorf("unexpected upload: %s", uploaded)
+	}
+
+	expectedCalls := "/api/5.0/getapplist.do,/api/5.0/uploadfile.do,/api/5.0/beginprescan.do"
+	if strings.Join(calls, ",") != expectedCalls {
+		t.Errorf("Got: %v", calls)
+		t.Errorf("Expected: %v", expectedCalls)
+	}
+
+	if err := UploadArchive(client, "unknown-app", archivePath, false); err == nil {
+		t.Error("expected an error for an unknown application profile")
+	}
+
+	t.Setenv(veracodeAPIKeySecretEnv, "")
+	if _, err := NewVeracodeClientFromEnv(server.URL); err == nil {
+		t.Error("expected an error for missing credentials")
+	}
+}
+
+// a failed upload (here: the server rejects it without reading the body) must not block the streaming of the archive
+func TestUploadFileRejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+	}))
+	defer server.Close()
+
+	client := &VeracodeClient{BaseURL: server.URL, KeyID: testKeyID, KeySecret: testKeySecret, HTTPClient: server.Client()}
+
+	archivePath := filepath.Join(t.TempDir(), "vc-output.zip")
+	if err := os.WriteFile(archivePath, make([]byte, 8*1024*1024), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.UploadFile("1337", archivePath); err == nil {
+		t.Error("expected an error for a rejected upload")
+	}
+
+	if err := client.UploadFile("1337", filepath.Join(t.TempDir(), "missing.zip")); err == nil {
+		t.Error("expected an error for a missing archive")
+	}
+}
@@ -1,7 +1,7 @@
 package main
 
 import (
-	"os"
+	"fmt"
 	"strings"
 
 	log "github.com/sirupsen/logrus"
@@ -9,7 +9,7 @@ import (
 
 // flags to make sure a message is only logged once
 var didPrintNodeModulesMsg bool = false
-var didPrintTestsMsg bool = false
+var didPrintTestsMsg = map[string]bool{}
 var didPrintDefaultTestExtensionsMsg bool = false
 var didPrintDefaultTestFoldersMsg bool = false
 var didPrintStylesheetsMsg bool = false
@@ -28,28 +28,150 @@ var didPrintVideoMsg bool = false
 var didPringIsMinified bool = false
 var didPrintArchiveMsg bool = false
 
+// resets the flags above, so that the messages are logged again by the next run
+func resetPrintedMessages() {
+	didPrintNodeModulesMsg = false
+	didPrintTestsMsg = map[string]bool{}
+	didPrintDefaultTestExtensionsMsg = false
+	didPrintDefaultTestFoldersMsg = false
+	didPrintStylesheetsMsg = false
+	didPrintImagesMsg = false
+	didPrintDocumentsMsg = false
+	didPrintFontsMsg = false
+	didPrintIdesMsg = false
+	didPrintBuildMsg = false
+	didPrintPublicMsg = false
+	didPrintDistMsg = false
+	didPrintDbsMsg = false
+	didPrintAngularFolderMsg = false
+	didPrintGitFolderMsg = false
+	didPrintBowerComponentsMsg = false
+	didPrintVideoMsg = false
+	didPringIsMinified = false
+	didPrintArchiveMsg = false
+}
+
+// a rule that omits files based on their extension (i.e., based on how their file name ends)
+type ExtensionRule struct {
+	// the name of the rule, e.g. used by `-case-sensitive` and in the manifest
+	Name       string
+	Extensions []string
+	// by default, extensions are compared case-insensitively (so e.g. `logo.PNG` matches `.png`)
+	CaseSensitive bool
+}
+
+// the extensions are the ones of the enabled test heuristics (see `applyTestHeuristics()`)
+var testFileRule = &ExtensionRule{Name: "test-files"}
+var styleSheetRule = &ExtensionRule{
+	Name:       "stylesheets",
+	Extensions: []string{".css", ".scss", ".sass", ".less", ".styl", ".stylus", ".pcss", ".postcss"},
+}
+var imageRule = &ExtensionRule{
+	Name:       "images",
+	Extensions: []string{".jpg", ".png", ".jpeg", ".gif", ".svg", ".bmp", ".ico", ".icns"},
+}
+
+// inspired by https://en.wikipedia.org/wiki/List_of_Microsoft_Office_filename_extensions (and additionally `.md`). The
+// extensions of Access keep their original (upper case) spelling, which is what `-case-sensitive documents` matches
+var documentRule = &ExtensionRule{
+	Name: "documents",
+	Extensions: []string{
+		".pdf",
+		".md",
+		".doc", ".dot", ".wbk", ".docx", ".docm", ".dotx", ".dotm", ".docb", ".wll", ".wwl",
+		".xls", ".xlt", ".xlm", ".xll_", ".xla_", ".xla5", ".xla8",
+		".xlsx", ".xlsm", ".xltx", ".xltm",
+		".ppt", ".pot", ".pps", ".pptx", ".pptm", ".potx", ".potm",
+		".one", ".ecf",
+		".ACCDA", ".ACCDB", ".ACCDE", ".ACCDT", ".MDA", ".MDE",
+	},
+}
+
+// inspired by this list: https://en.wikipedia.org/wiki/Video_file_format
+var videoRule = &ExtensionRule{
+	Name: "videos",
+	Extensions: []string{
+		".mp4", ".webm", ".mkv", ".flv", ".vob", ".ogv", ".drc", ".gifv", ".mng", ".avi", ".mov", ".qt", ".mts", ".wmv", ".amv",
+		".svi", ".m4v", ".mpg",
+	},
+}
+var fontRule = &ExtensionRule{Name: "fonts", Extensions: []string{".ttf", ".otf", ".woff", ".woff2"}}
+var dbRule = &ExtensionRule{Name: "dbs", Extensions: []string{".db", ".db3", ".sdb", ".sqlite", ".sqlite2", ".sqlite3"}}
+var minifiedRule = &ExtensionRule{Name: "minified", Extensions: []string{".js.map", ".min.js"}}
+var archiveRule = &ExtensionRule{Name: "archives", Extensions: []string{".zip", ".zipx", ".gz", ".tar", ".gzip", ".7z", ".rar"}}
+var miscExtensionRule = &ExtensionRule{Name: "misc", Extensions: []string{".d.ts", ".spec.json", ".bcmap"}}
+
+// all extension-based rules (e.g. to look them up by name)
+var extensionRules = []*ExtensionRule{
+	testFileRule, styleSheetRule, imageRule, documentRule, videoRule, fontRule, dbRule, minifiedRule, archiveRule,
+	miscExtensionRule,
+}
+
+// check if the path matches the rule. The second return value is `true` if the path only matched because extensions are
+// compared case-insensitively (e.g. `logo.PNG` for `.png`)
+func (rule *ExtensionRule) Match(path string) (bool, bool) {
+	if HasExtension(path, rule.Extensions...) {
+		return true, false
+	}
+
+	if !rule.CaseSensitive && HasExtensionFold(path, rule.Extensions...) {
+		return true, true
+	}
+
+	return false, false
+}
+
+// check if the path matches the rule (see `Match()`)
+func (rule *ExtensionRule) Matches(path string) bool {
+	matches, _ := rule.Match(path)
+	return matches
+}
+
+// returns the extension-based rule with the provided name (or `nil` if there is none)
+func GetExtensionRule(name string) *ExtensionRule {
+	for _, rule := range extensionRules {
+		if rule.Name == name {
+			return rule
+		}
+	}
+
+	return nil
+}
+
+// makes the extension-based rules with the provided names (like `images,documents`) compare extensions case-sensitively
+func SetCaseSensitiveExtensionRules(names string) error {
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		rule := GetExtensionRule(name)
+		if rule == nil {
+			return fmt.Errorf("unknown extension rule `%s`", name)
+		}
+
+		rule.CaseSensitive = true
+	}
+
+	return nil
+}
+
 // check for the `package-lock.json`, `yarn.lock` or `bower.json` (required for SCA)
 func CheckIfSCAFileExists(path string) bool {
 	// we don't want to look for `package-lock.json` and `yarn.lock` within `bower_components`
-	if !strings.Contains(path, "bower_components") {
-		packageManagerFiles := [2]string{"package-lock.json", "yarn.lock"}
-
-		for _, element := range packageManagerFiles {
-			if strings.HasSuffix(path, element) {
-				return true
-			}
-		}
+	if !HasPathSegment(path, "bower_components") && HasBaseName(path, "package-lock.json", "yarn.lock") {
+		return true
 	}
 
 	// NOTE: It looks like the `bower.json` file would be in `bower_components`? (tbh, I am not 100% sure how Bower
 	// works exactly, but it's been depreacted like forever and I can't really be bothered looking into how exactly it works)
-	bowerFile := "bower.json"
-	return strings.HasSuffix(path, bowerFile)
+	return HasBaseName(path, "bower.json")
 }
 
 // check for the `node_modules` folder
 func IsNodeModules(path string) bool {
-	if strings.Contains(path, string(os.PathSeparator)+"node_modules") {
+	if HasPathSegment(path, "node_modules") {
 		if !didPrintNodeModulesMsg {
 			log.Info("\tIgnoring the entire `node_modules` folder")
 			didPrintNodeModulesMsg = true
@@ -79,68 +201,72 @@ func IsBowerComponents(path string) bool {
 }
 
 // check if it is a `test` path (i.e., a file that e.g. contains unit tests)
-func IsInTestFolder(path string, testsPath string) bool {
+func IsInTestFolder(path string, testsPaths []string) bool {
 	// Test folders are treated as follows:
-	// 	- if `-tests` is provided, then only the provided path will be treated as a test directory (and thus, excluded)
-	// 	- if `-tests` is not provided, then `IsCommonTest()` will be called to exclude common test folders
-	if testsPath == "" {
-		return IsCommonTestFolder(path)
-	}
-
-	// At this point, `testsPath` may have a value like this: "sample-node-project/test".
-	// Thus, we want to do 2 things:
-	//	  - Exclude this folder itself, i.e. check for a path that ends with "sample-node-project/test"
-	// 	  - Exclude any file in it, i.e. check for path that contains "sample-node-project/test/"
-	fileInTestFolderPath := testsPath + string(os.PathSeparator)
+	// 	- if `-tests` is provided, then the provided paths will be treated as test directories (and thus, excluded)
+	// 	- if `-tests` is not provided (or `-tests-mode extend` is used), then `IsCommonTestFolder()` will be called to
+	// 	  exclude common test folders
+	//
+	// The provided paths may have values like this: "/test", "/some/tests" or "/src/**/__mocks__".
+	// For folders, we want to exclude the folder itself as well as any file in it, i.e. check if the segments of the
+	// folder appear in the path (so "/test" matches "/more/test/some.js", but not "/attest/some.js")
+	for _, testsPath := range testsPaths {
+		if MatchesTestsPath(path, testsPath) {
+			if !didPrintTestsMsg[testsPath] {
+				log.Info("\tIgnoring the entire content of `" + testsPath + "` (contains test files)")
+				didPrintTestsMsg[testsPath] = true
+			}
 
-	if strings.HasSuffix(path, testsPath) || strings.Contains(path, fileInTestFolderPath) {
-		if !didPrintTestsMsg {
-			log.Info("\tIgnoring the entire content of the `" + testsPath + "` folder (contains test files)")
-			didPrintTestsMsg = true
+			return true
 		}
+	}
 
-		return true
+	if UsesTestHeuristics(testsPaths) {
+		return IsCommonTestFolder(path)
 	}
 
 	return false
 }
 
 func IsCommonTestFolder(path string) bool {
-	testPaths := [4]string{"test", "tests", "e2e", "__tests__"}
-
-	for _, testPath := range testPaths {
-		// Here, we want to do 2 things:
-		//	  - Exclude the `testPath` itself, i.e. check for a path that ends e.g. with "/e2e"
-		// 	  - Exclude any file in it, i.e. check for path that contains e.g. "/e2e/"
-		testFolderPath := string(os.PathSeparator) + testPath
-		fileInTestFolderPath := testFolderPath + string(os.PathSeparator)
-
-		if strings.HasSuffix(path, testFolderPath) || strings.Contains(path, fileInTestFolderPath) {
-			if !didPrintDefaultTestFoldersMsg {
-				log.Info("\tIgnoring common test folders (such as `e2e`)")
-				didPrintDefaultTestFoldersMsg = true
-			}
+	// exclude the test folders of the enabled test heuristics themselves (e.g. "/e2e") as well as any file in them (e.g.
+	// "/e2e/some.js")
+	isTestFolder := false
+	for _, heuristic := range testHeuristics {
+		if heuristic.Enabled && heuristic.MatchesFolder(path) {
+			isTestFolder = true
+			break
+		}
+	}
 
-			return true
+	if isTestFolder {
+		if !didPrintDefaultTestFoldersMsg {
+			log.Info("\tIgnoring common test folders (such as `e2e`)")
+			didPrintDefaultTestFoldersMsg = true
 		}
+
+		return true
 	}
 
 	return false
 }
 
-// check for common test files (like .spec.js)
+// check for common test files (like .spec.js or the `test.ts` of Angular)
 func IsTestFile(path string) bool {
-	testExtensions := []string{".spec.ts", ".spec.tsx", ".test.ts", ".test.tsx", ".spec.js", ".spec.jsx", ".test.js", ".test.jsx"}
-
-	for _, element := range testExtensions {
-		if strings.HasSuffix(path, element) {
-			if !didPrintDefaultTestExtensionsMsg {
-				log.Info("\tIgnoring common test extensions (such as `.spec.ts`)")
-				didPrintDefaultTestExtensionsMsg = true
-			}
+	isTestFile := testFileRule.Matches(path)
+	for _, heuristic := range testHeuristics {
+		if heuristic.Enabled && !isTestFile && !strings.HasSuffix(path, "/") {
+			isTestFile = heuristic.MatchesFileName(path)
+		}
+	}
 
-			return true
+	if isTestFile {
+		if !didPrintDefaultTestExtensionsMsg {
+			log.Info("\tIgnoring common test extensions (such as `.spec.ts`)")
+			didPrintDefaultTestExtensionsMsg = true
 		}
+
+		return true
 	}
 
 	return false
@@ -148,7 +274,7 @@ func IsTestFile(path string) bool {
 
 // check for style sheets (like .css and .scss)
 func IsStyleSheet(path string) bool {
-	if strings.HasSuffix(path, ".css") || strings.HasSuffix(path, ".scss") {
+	if styleSheetRule.Matches(path) {
 		if !didPrintStylesheetsMsg {
 			log.Info("\tIgnoring style sheets (such as `.css`)")
 			didPrintStylesheetsMsg = true
@@ -162,17 +288,13 @@ func IsStyleSheet(path string) bool {
 
 // check for images (like .jpg, .png, .jpeg)
 func IsImage(path string) bool {
-	imageExtensions := [8]string{".jpg", ".png", ".jpeg", ".gif", ".svg", ".bmp", ".ico", ".icns"}
-
-	for _, element := range imageExtensions {
-		if strings.HasSuffix(path, element) {
-			if !didPrintImagesMsg {
-				log.Info("\tIgnoring images (such as `.jpg`)")
-				didPrintImagesMsg = true
-			}
-
-			return true
+	if imageRule.Matches(path) {
+		if !didPrintImagesMsg {
+			log.Info("\tIgnoring images (such as `.jpg`)")
+			didPrintImagesMsg = true
 		}
+
+		return true
 	}
 
 	return false
@@ -180,27 +302,13 @@ func IsImage(path string) bool {
 
 // check for documents (like .pdf, .md)
 func IsDocument(path string) bool {
-	// inspired by https://en.wikipedia.org/wiki/List_of_Microsoft_Office_filename_extensions (and additionally `.md`)
-	documentExtensions := [38]string{
-		".pdf",
-		".md",
-		".doc", ".dot", ".wbk", ".docx", ".docm", ".dotx", ".dotm", ".docb", ".wll", ".wwl",
-		".xls", ".xlt", ".xlm", ".xll_", ".xla_", ".xla5", ".xla8",
-		".xlsx", ".xlsm", ".xltx", ".xltm",
-		".ppt", ".pot", ".pps", ".pptx", ".pptm", ".potx", ".potm",
-		".one", ".ecf",
-		".ACCDA", ".ACCDB", ".ACCDE", ".ACCDT", ".MDA", ".MDE",
-	}
-
-	for _, element := range documentExtensions {
-		if strings.HasSuffix(path, element) {
-			if !didPrintDocumentsMsg {
-				log.Info("\tIgnoring documents (such as `.pdf`, `.docx`, `.md`)")
-				didPrintDocumentsMsg = true
-			}
-
-			return true
+	if documentRule.Matches(path) {
+		if !didPrintDocumentsMsg {
+			log.Info("\tIgnoring documents (such as `.pdf`, `.docx`, `.md`)")
+			didPrintDocumentsMsg = true
 		}
+
+		return true
 	}
 
 	return false
@@ -208,21 +316,13 @@ func IsDocument(path string) bool {
 
 // check for video files
 func IsVideo(path string) bool {
-	// inspired by this list: https://en.wikipedia.org/wiki/Video_file_format
-	videoExtensions := [18]string{
-		".mp4", ".webm", ".mkv", ".flv", ".vob", ".ogv", ".drc", ".gifv", ".mng", ".avi", ".mov", ".qt", ".mts", ".wmv", ".amv",
-		".svi", ".m4v", ".mpg",
-	}
-
-	for _, element := range videoExtensions {
-		if strings.HasSuffix(path, element) {
-			if !didPrintVideoMsg {
-				log.Info("\tIgnoring videos (such as `.mp4`)")
-				didPrintVideoMsg = true
-			}
-
-			return true
+	if videoRule.Matches(path) {
+		if !didPrintVideoMsg {
+			log.Info("\tIgnoring videos (such as `.mp4`)")
+			didPrintVideoMsg = true
 		}
+
+		return true
 	}
 
 	return false
@@ -230,17 +330,13 @@ func IsVideo(path string) bool {
 
 // check for fonts (like .woff)
 func IsFont(path string) bool {
-	fontExtensions := [4]string{".ttf", ".otf", ".woff", ".woff2"}
-
-	for _, element := range fontExtensions {
-		if strings.HasSuffix(path, element) {
-			if !didPrintFontsMsg {
-				log.Info("\tIgnoring fonts (such as `.woff`)")
-				didPrintFontsMsg = true
-			}
-
-			return true
+	if fontRule.Matches(path) {
+		if !didPrintFontsMsg {
+			log.Info("\tIgnoring fonts (such as `.woff`)")
+			didPrintFontsMsg = true
 		}
+
+		return true
 	}
 
 	return false
@@ -248,12 +344,8 @@ func IsFont(path string) bool {
 
 // check for the `.angular` folder
 func IsAngularCacheFolder(path string) bool {
-	// checks for the ".angular" folder itself, i.e. for a path that ends with `/.angular`
-	// ... or for files within the ".angular" folder, i.e. for a path that contains `/.angular/`
-	angularFolderPath := string(os.PathSeparator) + ".angular"
-	fileInAngularFolderPath := angularFolderPath + string(os.PathSeparator)
-
-	if strings.HasSuffix(path, angularFolderPath) || strings.Contains(path, fileInAngularFolderPath) {
+	// checks for the ".angular" folder itself (e.g. `/.angular`) or for files within it (e.g. `/.angular/some.js`)
+	if HasPathSegment(path, ".angular") {
 		if !didPrintAngularFolderMsg {
 			log.Info("\tIgnoring `.angular`")
 			didPrintAngularFolderMsg = true
@@ -267,12 +359,8 @@ func IsAngularCacheFolder(path string) bool {
 
 // check for the `.git` folder
 func IsGitFolder(path string) bool {
-	// checks for the ".git" folder itself, i.e. for a path that ends with `/.git`
-	// ... or for files within the ".git" folder, i.e. for a path that contains `/.git/`
-	gitFolderPath := string(os.PathSeparator) + ".git"
-	fileInGitFolderPath := gitFolderPath + string(os.PathSeparator)
-
-	if strings.HasSuffix(path, gitFolderPath) || strings.Contains(path, fileInGitFolderPath) {
+	// checks for the ".git" folder itself (e.g. `/.git`) or for files within it (e.g. `/.git/some.js`)
+	if HasPathSegment(path, ".git") {
 		if !didPrintGitFolderMsg {
 			log.Info("\tIgnoring `.git`")
 			didPrintGitFolderMsg = true
@@ -286,17 +374,13 @@ func IsGitFolder(path string) bool {
 
 // check for the dbs (like .db, .sqlite3)
 func IsDb(path string) bool {
-	documentExtensions := [6]string{".db", ".db3", ".sdb", ".sqlite", ".sqlite2", ".sqlite3"}
-
-	for _, element := range documentExtensions {
-		if strings.HasSuffix(path, element) {
-			if !didPrintDbsMsg {
-				log.Info("\tIgnoring dbs (such as `.sqlite3`)")
-				didPrintDbsMsg = true
-			}
-
-			return true
+	if dbRule.Matches(path) {
+		if !didPrintDbsMsg {
+			log.Info("\tIgnoring dbs (such as `.sqlite3`)")
+			didPrintDbsMsg = true
 		}
+
+		return true
 	}
 
 	return false
@@ -304,12 +388,8 @@ func IsDb(path string) bool {
 
 // check for the `build` folder
 func IsBuildFolder(path string) bool {
-	// checks for the "build" folder itself, i.e. for a path that ends with `/build`
-	// ... or for files within the "build" folder, i.e. for a path that contains `/build/`
-	buildFolderPath := string(os.PathSeparator) + "build"
-	fileInBuildFolderPath := buildFolderPath + string(os.PathSeparator)
-
-	if strings.HasSuffix(path, buildFolderPath) || strings.Contains(path, fileInBuildFolderPath) {
+	// checks for the "build" folder itself (e.g. `/build`) or for files within it (e.g. `/build/some.js`)
+	if HasPathSegment(path, "build") {
 		if !didPrintBuildMsg {
 			log.Info("\tIgnoring `build` folder")
 			didPrintBuildMsg = true
@@ -323,12 +403,8 @@ func IsBuildFolder(path string) bool {
 
 // check for the `dist` folder
 func IsDistFolder(path string) bool {
-	// checks for the "dist" folder itself, i.e. for a path that ends with `/dist`
-	// ... or for files within the "dist" folder, i.e. for a path that contains `/dist/`
-	distFolderPath := string(os.PathSeparator) + "dist"
-	fileInDistFolderPath := distFolderPath + string(os.PathSeparator)
-
-	if strings.HasSuffix(path, distFolderPath) || strings.Contains(path, fileInDistFolderPath) {
+	// checks for the "dist" folder itself (e.g. `/dist`) or for files within it (e.g. `/dist/some.js`)
+	if HasPathSegment(path, "dist") {
 		if !didPrintDistMsg {
 			log.Info("\tIgnoring `dist` folder")
 			didPrintDistMsg = true
@@ -342,12 +418,8 @@ func IsDistFolder(path string) bool {
 
 // check for the `public` folder
 func IsPublicFolder(path string) bool {
-	// checks for the "dist" folder itself, i.e. for a path that ends with `/dist`
-	// ... or for files within the "dist" folder, i.e. for a path that contains `/dist/`
-	publicFolderPath := string(os.PathSeparator) + "public"
-	fileInPublicFolderPath := publicFolderPath + string(os.PathSeparator)
-
-	if strings.HasSuffix(path, publicFolderPath) || strings.Contains(path, fileInPublicFolderPath) {
+	// checks for the "public" folder itself (e.g. `/public`) or for files within it (e.g. `/public/some.js`)
+	if HasPathSegment(path, "public") {
 		if !didPrintPublicMsg {
 			log.Info("\tIgnoring `public` folder")
 			didPrintPublicMsg = true
@@ -361,19 +433,13 @@ func IsPublicFolder(path string) bool {
 
 // check for IDE folder (like .code, .idea)
 func IsIdeFolder(path string) bool {
-	idePaths := [2]string{".vscode", ".idea"}
-
-	for _, element := range idePaths {
-		// NOTE: This check should be fine using "Contains" because I don't expect these IDE folder names to be used for
-		// 	anything useful (i.e., I don't anticipate a folder name with ".idea" in its name to contain anything useful)
-		if strings.Contains(path, string(os.PathSeparator)+element) {
-			if !didPrintIdesMsg {
-				log.Info("\tIgnoring IDE folder (such as .code, .idea)")
-				didPrintIdesMsg = true
-			}
-
-			return true
+	if HasPathSegment(path, ".vscode", ".idea") {
+		if !didPrintIdesMsg {
+			log.Info("\tIgnoring IDE folder (such as .code, .idea)")
+			didPrintIdesMsg = true
 		}
+
+		return true
 	}
 
 	return false
@@ -381,7 +447,7 @@ func IsIdeFolder(path string) bool {
 
 // check for minified JS
 func IsMinified(path string) bool {
-	if strings.HasSuffix(path, ".js.map") || strings.HasSuffix(path, ".min.js") {
+	if minifiedRule.Matches(path) {
 		if !didPringIsMinified {
 			log.Info("\tDropping minified JS (i.e., `.js.map` and `.min.js` files)")
 			didPringIsMinified = true
@@ -395,17 +461,13 @@ func IsMinified(path string) bool {
 
 // check for archives
 func IsArchive(path string) bool {
-	archiveExtensions := []string{".zip", ".zipx", ".gz", ".tar", ".gzip", ".7z", ".rar"}
-
-	for _, element := range archiveExtensions {
-		if strings.HasSuffix(path, element) {
-			if !didPrintArchiveMsg {
-				log.Info("\tIgnoring nested archives (such as `.zip`)")
-				didPrintArchiveMsg = true
-			}
-
-			return true
+	if archiveRule.Matches(path) {
+		if !didPrintArchiveMsg {
+			log.Info("\tIgnoring nested archives (such as `.zip`)")
+			didPrintArchiveMsg = true
 		}
+
+		return true
 	}
 
 	return false
@@ -413,17 +475,15 @@ func IsArchive(path string) bool {
 
 // check for the "misc" not required stuff
 func IsMiscNotRequiredFile(path string) bool {
-	notRequiredSuffices := []string{
-		".DS_Store", "__MACOSX", ".gitignore", ".gitkeep", ".gitattributes", ".npmignore", "CNAME", "tsconfig.json",
+	// files that are not required, matched by their exact file name (so e.g. `LICENSE` does not match `MY_LICENSE`)
+	notRequiredFileNames := []string{
+		".DS_Store", ".gitignore", ".gitkeep", ".gitattributes", ".npmignore", "CNAME", "tsconfig.json",
 		"tslint.json", "karma.conf.js", "angular.json", ".travis.yml", ".browserslistrc", ".editorconfig",
-		".d.ts", "protractor.conf.js", ".spec.json", "tsconfig.app.json", "polyfills.ts", "LICENSE", ".bcmap",
+		"protractor.conf.js", "tsconfig.app.json", "polyfills.ts", "LICENSE",
 	}
-
-	for _, element := range notRequiredSuffices {
-		if strings.HasSuffix(path, element) {
-			// NOTE: At the moment, these "misc" files aren't logged to avoid logging too much
-			return true
-		}
+	if HasPathSegment(path, "__MACOSX") || HasBaseName(path, notRequiredFileNames...) || miscExtensionRule.Matches(path) {
+		// NOTE: At the moment, these "misc" files aren't logged to avoid logging too much
+		return true
 	}
 
 	return false
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// if `false`, vendored 3rd party libraries outside of `node_modules` are kept (set via `-keep-vendored`)
+var vendoredDetectionEnabled bool = true
+
+// only the beginning of a file is searched for license banners and version headers
+const vendoredBannerSize = 4096
+
+// a well-known 3rd party library that is commonly copied into first-party folders (like `src/lib/`)
+type knownLibrary struct {
+	// the name of the npm package
+	name string
+	// matches the file names of the library, e.g. `jquery.js`, `jquery-3.6.0.js` or `jquery.slim.min.js`
+	fileName *regexp.Regexp
+	// matches the license banner of the library, optionally capturing its version
+	banner *regexp.Regexp
+}
+
+// a vendored 3rd party library that was found outside of `node_modules`
+type VendoredLibrary struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	// how the library was detected, e.g. "license banner"
+	DetectedVia string `json:"detectedVia"`
+}
+
+// returns a regex that matches the usual file names of a library, e.g. `jquery.js`, `jquery-3.6.0.min.js`,
+// `react-dom.production.min.js`
+func libraryFileName(name string) *regexp.Regexp {
+	return regexp.MustCompile(`(?i)^` + regexp.QuoteMeta(name) +
+		`([.-]v?\d+(\.\d+)*)?(\.(min|slim|umd|production|development|esm|bundle))*\.(js|mjs|cjs)$`)
+}
+
+var knownLibraries = []knownLibrary{
+	{"jquery", libraryFileName("jquery"), regexp.MustCompile(`jQuery (?:JavaScript Library )?v(\d+\.\d+\.\d+)`)},
+	{"jquery-ui", libraryFileName("jquery-ui"), regexp.MustCompile(`jQuery UI - v(\d+\.\d+\.\d+)`)},
+	{"lodash", libraryFileName("lodash"), regexp.MustCompile(`Lodash <https://lodash\.com/>|lodash\.com/license`)},
+	{"underscore", libraryFileName("underscore"), regexp.MustCompile(`Underscore\.js (\d+\.\d+\.\d+)`)},
+	{"bootstrap", libraryFileName("bootstrap"), regexp.MustCompile(`Bootstrap v(\d+\.\d+\.\d+)`)},
+	{"@popperjs/core", libraryFileName("popper"), regexp.MustCompile(`(?:@popperjs/core|Popper\.js) v?(\d+\.\d+\.\d+)`)},
+	{"angular", libraryFileName("angular"), regexp.MustCompile(`@license AngularJS v(\d+\.\d+\.\d+)`)},
+	{"react-dom", libraryFileName("react-dom"), regexp.MustCompile(`@license React v(\d+\.\d+\.\d+)\s*\*\s*react-dom`)},
+	{"react", libraryFileName("react"), regexp.MustCompile(`@license React v(\d+\.\d+\.\d+)`)},
+	{"vue", libraryFileName("vue"), regexp.MustCompile(`Vue\.js v(\d+\.\d+\.\d+)`)},
+	{"backbone", libraryFileName("backbone"), regexp.MustCompile(`Backbone\.js (\d+\.\d+\.\d+)`)},
+	{"knockout", libraryFileName("knockout"), regexp.MustCompile(`Knockout JavaScript library v(\d+\.\d+\.\d+)`)},
+	{"moment", libraryFileName("moment"), regexp.MustCompile(`//! moment\.js(?:\s*//! version : (\d+\.\d+\.\d+))?`)},
+	{"d3", libraryFileName("d3"), regexp.MustCompile(`https://d3js\.org v(\d+\.\d+\.\d+)`)},
+	{"chart.js", libraryFileName("chart"), regexp.MustCompile(`Chart\.js v(\d+\.\d+\.\d+)`)},
+	{"three", libraryFileName("three"), regexp.MustCompile(`Three\.js Authors`)},
+	{"axios", libraryFileName("axios"), regexp.MustCompile(`[Aa]xios v(\d+\.\d+\.\d+)`)},
+	{"handlebars", libraryFileName("handlebars"), regexp.MustCompile(`handlebars v(\d+\.\d+\.\d+)`)},
+	{"modernizr", libraryFileName("modernizr"), regexp.MustCompile(`[Mm]odernizr (\d+\.\d+\.\d+)`)},
+	{"requirejs", libraryFileName("require"), regexp.MustCompile(`RequireJS (\d+\.\d+\.\d+)`)},
+	{"socket.io-client", libraryFileName("socket.io"), regexp.MustCompile(`Socket\.IO v(\d+\.\d+\.\d+)`)},
+}
+
+// matches a version header in the beginning of a file, like `@version 1.2.3` or `v1.2.3`
+var versionHeaderRegex = regexp.MustCompile(`(?i)(?:@version\s+|\bv)(\d+\.\d+\.\d+)`)
+
+// check if the file name is the one of a well-known 3rd party library (like `jquery-3.6.0.js`)
+func IsKnownLibraryFileName(path string) bool {
+	return getLibraryByFileName(path) != nil
+}
+
+func getLibraryByFileName(path string) *knownLibrary {
+	base := BaseName(path)
+
+	for i := range knownLibraries {
+		if knownLibraries[i].fileName.MatchString(base) {
+			return &knownLibraries[i]
+		}
+	}
+
+	return nil
+}
+
+// detects a vendored 3rd party library by its license banner or, if the file name is the one of a known library, by a
+// version header or a vendor folder (like `assets/js/vendor/`). Returns `nil` for first-party code
+func DetectVendoredLibrary(path string, content []byte) *VendoredLibrary {
+	head := content
+	if len(head) > vendoredBannerSize {
+		head = head[:vendoredBannerSize]
+	}
+
+	for _, library := range knownLibraries {
+		if match := library.banner.FindSubmatch(head); match != nil {
+			vendoredLibrary := &VendoredLibrary{Name: library.name, DetectedVia: "license banner"}
+			if len(match) > 1 {
+				vendoredLibrary.Version = string(match[1])
+			}
+
+			return vendoredLibrary
+		}
+	}
+
+	// a file name alone (like `chart.js`) is not enough, since first-party code may use the same name
+	library := getLibraryByFileName(path)
+	if library == nil {
+		return nil
+	}
+
+	if match := versionHeaderRegex.FindSubmatch(head); match != nil {
+		return &VendoredLibrary{Name: library.name, Version: string(match[1]), DetectedVia: "file name and version header"}
+	}
+
+	if HasPathSegment(path, "vendor", "vendors", "lib", "libs", "third-party", "third_party") {
+		return &VendoredLibrary{Name: library.name, DetectedVia: "file name in a vendor folder"}
+	}
+
+	return nil
+}
+
+// returns e.g. `jquery@3.6.0` (or only `jquery` if the version is unknown)
+func (library *VendoredLibrary) String() string {
+	if library.Version == "" {
+		return library.Name
+	}
+
+	return library.Name + "@" + library.Version
+}
+
+// returns the note for the manifest, e.g. "vendored library `jquery@3.6.0` (detected via license banner)"
+func (library *VendoredLibrary) Note() string {
+	return fmt.Sprintf("vendored library `%s` (detected via %s)", library.String(), library.DetectedVia)
+}
+
+// returns the command to add the vendored libraries as proper dependencies (so that Veracode SCA covers them)
+func GetInstallCommand(libraries []*VendoredLibrary) string {
+	seen := map[string]bool{}
+	var packages []string
+
+	for _, library := range libraries {
+		if !seen[library.String()] {
+			seen[library.String()] = true
+			packages = append(packages, library.String())
+		}
+	}
+
+	return "npm install --save " + strings.Join(packages, " ")
+}
+
+// logs the vendored libraries that were omitted (and adds a smell), so that they can be added as proper dependencies
+func ReportVendoredLibraries(manifest *Manifest) {
+	var libraries []*VendoredLibrary
+	var found []string
+
+	for _, entry := range manifest.Entries {
+		if entry.Library != nil {
+			libraries = append(libraries, entry.Library)
+			found = append(found, fmt.Sprintf("%s (%s)", entry.Library.String(), entry.Path))
+		}
+	}
+
+	if len(libraries) == 0 {
+		return
+	}
+
+	log.Warn("\tOmitted ", len(libraries), " vendored 3rd party librar(y/ies) outside of `node_modules`:")
+	for _, library := range found {
+		log.Warn("\t\t- ", library)
+	}
+	log.Warn("\tTo get Veracode SCA results for them, consider adding them as dependencies, e.g. via `", GetInstallCommand(libraries), "`")
+
+	manifest.Smells = append(manifest.Smells, fmt.Sprintf("%d vendored 3rd party librar(y/ies) found outside of `node_modules` "+
+		"(not covered by Veracode SCA): %s", len(libraries), strings.Join(found, ", ")))
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Tests the detection of vendored 3rd party libraries by their license banner, file name and version header
+func TestDetectVendoredLibrary(t *testing.T) {
+	testCases := []struct {
+		path     string
+		content  string
+		expected string
+	}{
+		{"/src/lib/jq.js", "/*! jQuery v3.6.0 | (c) OpenJS Foundation and other contributors | jquery.org/license */", "jquery@3.6.0"},
+		{"/src/util.js", "/**\n * @license\n * Lodash <https://lodash.com/>\n */", "lodash"},
+		{"/assets/bs.js", "/*!\n  * Bootstrap v5.2.3 (https://getbootstrap.com/)\n  */", "bootstrap@5.2.3"},
+		{"/src/react-dom.js", "/** @license React v16.13.1\n * react-dom.production.min.js\n */", "react-dom@16.13.1"},
+		{"/src/chart.js", "/**\n * @version 2.9.4\n */", "chart.js@2.9.4"},
+		{"/assets/js/vendor/moment.js", "(function (global, factory) {})", "moment"},
+		{"/src/chart.js", "// our own chart component\nexport function chart() {}", ""},
+		{"/src/lib/helpers.js", "// v1.0.0 of our helpers", ""},
+		{"/src/reactUtils.js", "export const x = 1;", ""},
+	}
+
+	for _, testCase := range testCases {
+		library := DetectVendoredLibrary(testCase.path, []byte(testCase.content))
+
+		got := ""
+		if library != nil {
+			got = library.String()
+		}
+
+		if got != testCase.expected {
+			t.Errorf("detected `%s` for `%s`, expected `%s`", got, testCase.path, testCase.expected)
+		}
+	}
+}
+
+// Integration test for `zipSource()` with vendored libraries in first-party folders
+func TestZipSourceWithVendoredLibraries(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+
+	source := writeTestFiles(t, map[string]string{
+		"package.json":                 `{"name": "legacy-app"}`,
+		"src/app.js":                   "$(function () {\n  console.log('app');\n});\n",
+		"src/lib/jquery.js":            "/*!\n * jQuery JavaScript Library v3.6.0\n */\n",
+		"assets/js/vendor/lodash.js":   "/**\n * @license\n * Lodash <https://lodash.com/>\n */\n",
+		"assets/js/vendor/our-code.js": "console.log('first-party');\n",
+	})
+
+	zipFileContents := generateZipAndReturnItsFiles(source, filepath.Join(t.TempDir(), "test-output.zip"), nil)
+	expectedFilesInOutputZip := []string{"package.json", "src/app.js", "assets/js/vendor/our-code.js"}
+
+	sort.Strings(expectedFilesInOutputZip)
+	sort.Strings(zipFileContents)
+
+	if !reflect.DeepEqual(zipFileContents, expectedFilesInOutputZip) {
+		t.Errorf("Got: %v", zipFileContents)
+		t.Errorf("Expected: %v", expectedFilesInOutputZip)
+	}
+
+	if command := GetInstallCommand([]*VendoredLibrary{{Name: "jquery", Version: "3.6.0"}, {Name: "lodash"}}); command != "npm install --save jquery@3.6.0 lodash" {
+		t.Errorf("unexpected install command `%s`", command)
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"archive/zip"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// the number of problems that are listed in the message of a `VerificationError`
+const maxListedVerificationProblems = 10
+
+// the archive does not contain what the packager decided to include (e.g. a file is missing, or differs from the source)
+type VerificationError struct {
+	Problems []string
+}
+
+func (err *VerificationError) Error() string {
+	problems := err.Problems
+	if len(problems) > maxListedVerificationProblems {
+		problems = append(problems[:maxListedVerificationProblems:maxListedVerificationProblems],
+			fmt.Sprintf("... and %d more", len(err.Problems)-maxListedVerificationProblems))
+	}
+
+	return fmt.Sprintf("the archive failed the verification (%d problem(s)): %s", len(err.Problems), strings.Join(problems, "; "))
+}
+
+// returns the CRC-32 (as used by zip) and the size of a file
+func getFileCRC32(path string) (uint32, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+
+	hash := crc32.NewIEEE()
+	size, err := io.Copy(hash, f)
+	return hash.Sum32(), size, err
+}
+
+// reopens the archive and verifies that it contains exactly the files the manifest includes: every entry is read
+// completely (which checks its CRC), and the CRC and size of every file of the source are compared with the ones of
+// its entry. Generated files (like recovered sources) only exist in the archive, so only their CRC is checked
+func VerifyArchive(archivePath string, manifest *Manifest) error {
+	reader, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return &VerificationError{Problems: []string{"it could not be opened: " + err.Error()}}
+	}
+	defer reader.Close()
+
+	var problems []string
+	entries := map[string]*zip.File{}
+	for _, file := range reader.File {
+		if strings.HasSuffix(file.Name, "/") {
+			continue
+		}
+
+		if entries[file.Name] != nil {
+			problems = append(problems, fmt.Sprintf("`%s` is contained more than once", file.Name))
+		}
+		entries[file.Name] = file
+	}
+
+	planned := map[string]bool{}
+	for _, entry := range manifest.Entries {
+		if !entry.Included {
+			continue
+		}
+
+		planned[entry.Path] = true
+		file := entries[entry.Path]
+		if file == nil {
+			problems = append(problems, fmt.Sprintf("`%s` is missing", entry.Path))
+			continue
+		}
+
+		if problem := verifyEntry(file, manifest.Source, entry); problem != "" {
+			problems = append(problems, problem)
+		}
+	}
+
+	var unexpected []string
+	for name := range entries {
+		if !planned[name] {
+			unexpected = append(unexpected, fmt.Sprintf("`%s` was not supposed to be included", name))
+		}
+	}
+	sort.Strings(unexpected)
+	problems = append(problems, unexpected...)
+
+	if len(problems) > 0 {
+		return &VerificationError{Problems: problems}
+	}
+
+	log.Info("\tVerified the ", len(planned), " file(s) of the archive")
+	return nil
+}
+
+// verifies a single entry of the archive, and returns the problem with it (or "" if there is none)
+func verifyEntry(file *zip.File, source string, entry ManifestEntry) string {
+	content, err := file.Open()
+	if err != nil {
+		return fmt.Sprintf("`%s` could not be read: %s", entry.Path, err)
+	}
+	defer content.Close()
+
+	// reading an entry completely makes `archive/zip` check its CRC
+	if _, err := io.Copy(io.Discard, content); err != nil {
+		return fmt.Sprintf("`%s` could not be read: %s", entry.Path, err)
+	}
+
+	if entry.Generated {
+		return ""
+	}
+
+	sourceCRC, sourceSize, err := getFileCRC32(filepath.Join(source, filepath.FromSlash(entry.Path)))
+	if err != nil {
+		return fmt.Sprintf("`%s` could not be read from the source: %s", entry.Path, err)
+	}
+
+	if uint64(sourceSize) != file.UncompressedSize64 {
+		return fmt.Sprintf("`%s` has %d byte(s) in the archive, but %d byte(s) in the source", entry.Path, file.UncompressedSize64, sourceSize)
+	}
+
+	if sourceCRC != file.CRC32 {
+		return fmt.Sprintf("`%s` differs from the source (CRC %08x instead of %08x)", entry.Path, file.CRC32, sourceCRC)
+	}
+
+	return ""
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// writes a zip with the provided files (and their content) to the target
+func writeVerifyTestArchive(t *testing.T, target string, files [][2]string) {
+	f, err := os.Create(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	writer := zip.NewWriter(f)
+	for _, file := range files {
+		fileWriter, err := writer.Create(file[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := fileWriter.Write([]byte(file[1])); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Integration test for `VerifyArchive()` with `./sample-projects/sample-node-project`
+func TestVerifyArchiveWithNodeSample(t *testing.T) {
+	log.SetLevel(log.FatalLevel)
+
+	target := filepath.Join(t.TempDir(), "test-output.zip")
+	manifest, err := zipSource("./sample-projects/sample-node-project", target, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := VerifyArchive(target, manifest); err != nil {
+		t.Error(err)
+	}
+
+	// the manifest no longer matches the archive if a file is planned, but not part of it
+	manifest.Add("src/added-later.js", PathDecision{})
+	if err := VerifyArchive(target, manifest); GetExitCode(err) != ExitVerificationFailed {
+		t.Errorf("Got: %v", err)
+	}
+}
+
+func TestVerifyArchiveFindsDiscrepancies(t *testing.T) {
+	source := writeTestFiles(t, map[string]string{
+		"app.js":        "console.log('app')",
+		"same-size.js":  "console.log('abc')",
+		"other-size.js": "console.log('a much longer file')",
+		"missing.js":    "console.log('missing')",
+	})
+
+	target := filepath.Join(t.TempDir(), "test-output.zip")
+	writeVerifyTestArchive(t, target, [][2]string{
+		{"app.js", "console.log('app')"},
+		{"same-size.js", "console.log('xyz')"},
+		{"other-size.js", "console.log('short')"},
+		{"recovered/src/index.ts", "export {}"},
+		{"unplanned.js", "console.log('unplanned')"},
+		{"app.js", "console.log('app')"},
+	})
+
+	manifest := &Manifest{Source: source, Archive: target}
+	for _, name := range []string{"app.js", "same-size.js", "other-size.js", "missing.js"} {
+		manifest.Add(name, PathDecision{})
+	}
+	manifest.Add("recovered/src/index.ts", PathDecision{Generated: true})
+	manifest.Add("node_modules/express/index.js", PathDecision{Rule: "node_modules"})
+
+	var verificationError *VerificationError
+	if err := VerifyArchive(target, manifest); !errors.As(err, &verificationError) {
+		t.Fatalf("Got: %v", err)
+	}
+
+	var problems []string
+	for _, problem := range verificationError.Problems {
+		// e.g. "`same-size.js` differs from the source (CRC ...)" becomes "same-size.js differs"
+		words := strings.Fields(strings.ReplaceAll(problem, "`", ""))
+		problems = append(problems, strings.Join(words[:2], " "))
+	}
+
+	expected := []string{"app.js is", "same-size.js differs", "other-size.js has", "missing.js is", "unplanned.js was"}
+	if !reflect.DeepEqual(problems, expected) {
+		t.Errorf("Got: %v", verificationError.Problems)
+		t.Errorf("Expected: %v", expected)
+	}
+}
+
+func TestVerificationErrorListsTheFirstProblems(t *testing.T) {
+	var problems []string
+	for i := 1; i <= 12; i++ {
+		problems = append(problems, fmt.Sprintf("`file-%d.js` is missing", i))
+	}
+
+	message := (&VerificationError{Problems: problems}).Error()
+	if !strings.Contains(message, "(12 problem(s))") || !strings.Contains(message, "`file-10.js`") ||
+		strings.Contains(message, "`file-11.js`") || !strings.HasSuffix(message, "... and 2 more") {
+		t.Errorf("Got: %s", message)
+	}
+
+	// the problems themselves are not changed by the message
+	if len(problems) != 12 || problems[10] != "`file-11.js` is missing" {
+		t.Errorf("Got: %v", problems)
+	}
+}
+
+// an archive that fails the verification is never moved to its target
+func TestAtomicArchiveIsNotCommittedIfTheVerificationFails(t *testing.T) {
+	targetDir := t.TempDir()
+	archive, err := CreateAtomicArchive(filepath.Join(targetDir, "test-output.zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := archive.Writer.Create("app.js"); err != nil {
+		t.Fatal(err)
+	}
+
+	archive.Verify = func(path string) error {
+		return VerifyArchive(path, &Manifest{Source: t.TempDir()})
+	}
+
+	if err := archive.Commit(nil); GetExitCode(err) != ExitVerificationFailed {
+		t.Errorf("Got: %v", err)
+	}
+
+	if names := listDirectory(t, targetDir); len(names) != 0 {
+		t.Errorf("Got: %v", names)
+	}
+}
@@ -6,9 +6,11 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"strings"
 
 	"github.com/fatih/color"
 	"github.com/hashicorp/go-version"
+	log "github.com/sirupsen/logrus"
 )
 
 var AppVersion string = "0.0.0"
@@ -44,14 +46,18 @@ func NotifyOfUpdates() {
 		return
 	}
 
-	vCurrent, err := version.NewVersion(AppVersion)
+	// the errors are logged (instead of printed), so that stdout only contains the summary of `-output json`
+	vCurrent, err := version.NewVersion(strings.TrimSpace(AppVersion))
 	if err != nil {
-		fmt.Print(err)
+		log.Debug("The current version is invalid: ", err)
+		return
 	}
 
-	vLatest, err := version.NewVersion(response["tag_name"].(string))
+	tagName, _ := response["tag_name"].(string)
+	vLatest, err := version.NewVersion(tagName)
 	if err != nil {
-		fmt.Print(err)
+		log.Debug("The latest release has an invalid version: ", err)
+		return
 	}
 
 	// check if a newer version exists in the GitHub Releases
@@ -64,7 +70,8 @@ func CheckAppVersion() {
 	if AppVersion == "0.0.0" {
 		version, err := os.ReadFile("current_version")
 		if err != nil {
-			fmt.Print(err)
+			log.Debug("The current version is unknown: ", err)
+			return
 		}
 
 		// manually assign the value from `./current_version` if it wasn't assigned during compilation already. This makes sure